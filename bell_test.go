@@ -0,0 +1,182 @@
+package readline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/reeflective/readline/inputrc"
+	"github.com/reeflective/readline/internal/term"
+)
+
+// newBellShell returns a shell counting the rings of its bell.
+func newBellShell(style string) (rl *Shell, rings *int) {
+	rl = NewShell()
+	rings = new(int)
+
+	rl.Config.Set("bell-style", style)
+	rl.Bell.OnRing(func() { *rings++ })
+
+	return rl, rings
+}
+
+func TestBellIsearch(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      string
+		wantRings int
+	}{
+		{
+			name:      "Search with matches",
+			keys:      "\x12g",
+			wantRings: 0,
+		},
+		{
+			name:      "Failed search",
+			keys:      "\x12gx",
+			wantRings: 1,
+		},
+		{
+			name:      "Other keys in a failed search",
+			keys:      "\x12gx\x02\x06y",
+			wantRings: 1,
+		},
+		{
+			name:      "Search failing again",
+			keys:      "\x12gx\x08\x08y",
+			wantRings: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rl, rings := newBellShell("none")
+			rl.History.Current().Write("git status")
+
+			_, output := readKeys(t, rl, test.keys)
+
+			if *rings != test.wantRings {
+				t.Errorf("bell rang %d times, want %d", *rings, test.wantRings)
+			}
+
+			if strings.Contains(output, term.Bell) {
+				t.Errorf("bell printed with bell-style none")
+			}
+		})
+	}
+}
+
+func TestBellCompletions(t *testing.T) {
+	followUp := Completion{Value: "-x", Accept: func(line []rune, cursor int) ([]rune, int, bool) {
+		return line, cursor, true
+	}}
+
+	tests := []struct {
+		name      string
+		keys      string
+		completer func(line []rune, cursor int) Completions
+		wantRings int
+	}{
+		{
+			name: "Completions",
+			keys: "ls \t",
+			completer: func(line []rune, cursor int) Completions {
+				return CompleteValues("file.go", "other.go")
+			},
+			wantRings: 0,
+		},
+		{
+			name: "No completions",
+			keys: "ls \t",
+			completer: func(line []rune, cursor int) Completions {
+				return Completions{}
+			},
+			wantRings: 1,
+		},
+		{
+			name: "Follow-up without completions",
+			keys: "tar -\t",
+			completer: func(line []rune, cursor int) Completions {
+				if strings.Contains(string(line), "-x") {
+					return Completions{}
+				}
+
+				return CompleteRaw([]Completion{followUp})
+			},
+			wantRings: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rl, rings := newBellShell("none")
+			rl.Completer = test.completer
+
+			_, output := readKeys(t, rl, test.keys)
+
+			if *rings != test.wantRings {
+				t.Errorf("bell rang %d times, want %d", *rings, test.wantRings)
+			}
+
+			if strings.Contains(output, term.Bell) {
+				t.Errorf("bell printed with bell-style none")
+			}
+		})
+	}
+}
+
+func TestBellHistory(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      string
+		wantLine  string
+		wantRings int
+	}{
+		{
+			name:      "Search with a match",
+			keys:      inputrc.Unescape(`\C-xscommit\r\r`),
+			wantLine:  "git commit -m",
+			wantRings: 0,
+		},
+		{
+			name:      "Failed search",
+			keys:      inputrc.Unescape(`\C-xsmerge\r\r`),
+			wantLine:  "",
+			wantRings: 1,
+		},
+		{
+			name:      "Yank an argument",
+			keys:      inputrc.Unescape(`\M-2\C-xy\r`),
+			wantLine:  "commit",
+			wantRings: 0,
+		},
+		{
+			name:      "Yank an argument out of range",
+			keys:      inputrc.Unescape(`\M-5\C-xy\r`),
+			wantLine:  "",
+			wantRings: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rl, rings := newBellShell("none")
+			rl.History.Current().Write("git commit -m")
+			rl.Config.Bind("emacs", inputrc.Unescape(`\C-xs`), "non-incremental-reverse-search-history", false)
+			rl.Config.Bind("emacs", inputrc.Unescape(`\C-xy`), "yank-nth-arg", false)
+
+			line, output := readKeys(t, rl, test.keys)
+
+			if line != test.wantLine {
+				t.Errorf("line = %q, want %q", line, test.wantLine)
+			}
+
+			if *rings != test.wantRings {
+				t.Errorf("bell rang %d times, want %d", *rings, test.wantRings)
+			}
+
+			if strings.Contains(output, term.Bell) {
+				t.Errorf("bell printed with bell-style none")
+			}
+		})
+	}
+}
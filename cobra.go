@@ -0,0 +1,109 @@
+package readline
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/reeflective/readline/internal/completion"
+)
+
+// Default limits of cobra completers.
+const (
+	defaultCobraTimeout   = 2 * time.Second
+	defaultCobraMaxOutput = 1 << 20
+)
+
+// CobraCompleter completes the command lines of programs built with cobra,
+// by running their hidden __complete command with the words of the line.
+// Its Complete method is to be used as the shell ContextCompleter:
+//
+//	shell.ContextCompleter = readline.NewCobraCompleter("kubectl").Complete
+type CobraCompleter struct {
+	Binary    string        // Name or path of the program.
+	Timeout   time.Duration // The program is killed if it does not complete in time (2s if 0).
+	MaxOutput int           // Maximum size of the program output, in bytes (1MB if 0).
+}
+
+// NewCobraCompleter returns a completer for a cobra program, with default limits.
+func NewCobraCompleter(binary string) *CobraCompleter {
+	return &CobraCompleter{
+		Binary:    binary,
+		Timeout:   defaultCobraTimeout,
+		MaxOutput: defaultCobraMaxOutput,
+	}
+}
+
+// Complete runs the program completion for the words preceding the cursor,
+// and maps the returned directives onto the completions. Unless the program
+// disables it, files are completed when there are no candidates.
+func (c *CobraCompleter) Complete(ctx CompletionContext) Completions {
+	// The first word is the program itself.
+	if ctx.Current == 0 {
+		return Completions{}
+	}
+
+	args := make([]string, 0, ctx.Current)
+	for _, word := range ctx.Words[1:ctx.Current] {
+		args = append(args, word.Value)
+	}
+
+	args = append(args, ctx.PrefixValue)
+
+	timeout, maxOutput := c.Timeout, c.MaxOutput
+	if timeout <= 0 {
+		timeout = defaultCobraTimeout
+	}
+
+	if maxOutput <= 0 {
+		maxOutput = defaultCobraMaxOutput
+	}
+
+	result, err := completion.RunCobra(c.Binary, args, timeout, maxOutput)
+	if err != nil {
+		return Completions{}.Error("%s: %s", filepath.Base(c.Binary), err)
+	}
+
+	directive := result.Directive
+	comps := CompleteRaw(result.Values)
+
+	switch {
+	case directive.Has(completion.CobraError):
+		comps = Completions{}.Error("%s: completion failed", filepath.Base(c.Binary))
+
+	case directive.Has(completion.CobraFilterFileExt):
+		extensions := make([]string, 0, len(result.Values))
+		for _, ext := range result.Values {
+			extensions = append(extensions, ext.Value)
+		}
+
+		comps = CompleteRaw(completion.Files("", ctx.PrefixValue, false, extensions...))
+		comps = comps.NoSpace('/')
+
+	case directive.Has(completion.CobraFilterDirs):
+		var root string
+		if len(result.Values) > 0 {
+			root = result.Values[0].Value
+		}
+
+		comps = CompleteRaw(completion.Files(root, ctx.PrefixValue, true))
+		comps = comps.NoSpace('/')
+
+	case len(result.Values) == 0 && !directive.Has(completion.CobraNoFileComp):
+		comps = CompleteRaw(completion.Files("", ctx.PrefixValue, false))
+		comps = comps.NoSpace('/')
+	}
+
+	for _, msg := range result.Messages {
+		comps.messages.Add(msg)
+	}
+
+	if directive.Has(completion.CobraNoSpace) {
+		comps = comps.NoSpace()
+	}
+
+	if directive.Has(completion.CobraKeepOrder) {
+		comps = comps.NoSort()
+	}
+
+	return comps
+}
@@ -0,0 +1,159 @@
+package readline
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCobraCompleter(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, path := range []string{"src", "docs"} {
+		if err := os.Mkdir(filepath.Join(dir, path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, path := range []string{"main.go", "README.md"} {
+		if err := os.WriteFile(filepath.Join(dir, path), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		output       string
+		word         string
+		want         []string
+		wantNoSpace  string // A value matching the no-space suffixes, if any.
+		wantNoSort   bool
+		wantMessages []string
+	}{
+		{
+			name:   "Candidates",
+			output: "get\tDisplay resources\napply\n:4",
+			want:   []string{"apply", "get"},
+		},
+		{
+			name:        "No space",
+			output:      "--namespace=\n:6",
+			want:        []string{"--namespace="},
+			wantNoSpace: "--namespace=",
+		},
+		{
+			name:       "Keep order",
+			output:     "pods\nnodes\n:36",
+			want:       []string{"nodes", "pods"},
+			wantNoSort: true,
+		},
+		{
+			name:         "Active help",
+			output:       "_activeHelp_ Usage: kubectl get TYPE\n:4",
+			wantMessages: []string{"Usage: kubectl get TYPE"},
+		},
+		{
+			name:         "Error",
+			output:       "pods\n:1",
+			wantMessages: []string{"stub: completion failed"},
+		},
+		{
+			name:        "Files without candidates",
+			output:      ":0",
+			word:        dir + "/",
+			want:        []string{dir + "/README.md", dir + "/docs/", dir + "/main.go", dir + "/src/"},
+			wantNoSpace: "src/",
+		},
+		{
+			name:   "No files without candidates",
+			output: ":4",
+			word:   dir + "/",
+		},
+		{
+			name:        "Files with extensions",
+			output:      "go\n:8",
+			word:        dir + "/",
+			want:        []string{dir + "/docs/", dir + "/main.go", dir + "/src/"},
+			wantNoSpace: "src/",
+		},
+		{
+			name:        "Directories in a directory",
+			output:      dir + "\n:16",
+			want:        []string{"docs/", "src/"},
+			wantNoSpace: "src/",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stub := filepath.Join(t.TempDir(), "stub")
+			script := "#!/bin/sh\ncat <<'EOF'\n" + test.output + "\nEOF\n"
+
+			if err := os.WriteFile(stub, []byte(script), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			line := "stub get " + test.word
+			ctx := newCompletionContext([]rune(line), len([]rune(line)))
+
+			comps := (&CobraCompleter{Binary: stub}).Complete(ctx)
+
+			var got []string
+			for _, val := range comps.values {
+				got = append(got, val.Value)
+			}
+
+			slices.Sort(got)
+
+			if !slices.Equal(got, test.want) {
+				t.Errorf("Complete() = %q, want %q", got, test.want)
+			}
+
+			if test.wantNoSpace != "" && !comps.noSpace.Matches(test.wantNoSpace) {
+				t.Errorf("Complete() no-space suffixes do not match %q", test.wantNoSpace)
+			}
+
+			if test.wantNoSpace == "" && comps.noSpace.Matches("get") {
+				t.Errorf("Complete() no-space suffixes match all values, want none")
+			}
+
+			if comps.noSort["*"] != test.wantNoSort {
+				t.Errorf("Complete() no-sort = %t, want %t", comps.noSort["*"], test.wantNoSort)
+			}
+
+			var messages []string
+			for _, msg := range comps.messages.All() {
+				messages = append(messages, msg.Text)
+			}
+
+			if !slices.Equal(messages, test.wantMessages) {
+				t.Errorf("Complete() messages = %q, want %q", messages, test.wantMessages)
+			}
+		})
+	}
+}
+
+func TestCobraCompleterLimits(t *testing.T) {
+	stub := filepath.Join(t.TempDir(), "stub")
+	if err := os.WriteFile(stub, []byte("#!/bin/sh\nwhile true; do echo candidate; done\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	line := "stub "
+	ctx := newCompletionContext([]rune(line), len(line))
+	start := time.Now()
+
+	// The default timeout applies, but the output limit is reached first.
+	comps := (&CobraCompleter{Binary: stub, MaxOutput: 1024}).Complete(ctx)
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Complete() took %s, want the program killed once its output is too large", elapsed)
+	}
+
+	if messages := comps.messages.All(); len(messages) != 1 || !strings.Contains(messages[0].Text, "too large") {
+		t.Errorf("Complete() messages = %v, want an output too large error", messages)
+	}
+}
@@ -21,6 +21,9 @@ func (rl *Shell) completionCommands() commands {
 		"menu-complete-next-tag":   rl.menuCompleteNextTag,
 		"menu-complete-prev-tag":   rl.menuCompletePrevTag,
 		"accept-and-menu-complete": rl.acceptAndMenuComplete,
+		"menu-toggle-mark":         rl.menuToggleMark,
+		"menu-preview-scroll-up":   rl.menuPreviewScrollUp,
+		"menu-preview-scroll-down": rl.menuPreviewScrollDown,
 		"vi-registers-complete":    rl.viRegistersComplete,
 		"menu-incremental-search":  rl.menuIncrementalSearch,
 	}
@@ -31,10 +34,24 @@ func (rl *Shell) completionCommands() commands {
 //
 
 // Attempt completion on the current word.
-// Currently identitical to menu-complete.
+// By default, this is identical to menu-complete.
+// If either of complete-common-prefix, show-all-if-ambiguous or show-all-if-unmodified
+// is on, this behaves like bash: the only candidate or the longest prefix common to all
+// candidates is inserted, and the bell rings if the completion is ambiguous. The next
+// attempt lists the candidates, unless they have been listed immediately because of the
+// show-all-if-* options.
 func (rl *Shell) completeWord() {
 	rl.History.SkipSave()
 
+	commonPrefix := rl.Config.GetBool("complete-common-prefix") ||
+		rl.Config.GetBool("show-all-if-ambiguous") ||
+		rl.Config.GetBool("show-all-if-unmodified")
+
+	if commonPrefix {
+		rl.completeCommonPrefix()
+		return
+	}
+
 	// This completion function should attempt to insert the first
 	// valid completion found, without printing the actual list.
 	if !rl.completer.IsActive() {
@@ -168,6 +185,41 @@ func (rl *Shell) acceptAndMenuComplete() {
 	rl.completer.Select(1, 0)
 }
 
+// In a menu completion, mark the current candidate (or unmark it if already marked),
+// and advance to the next possible completion. As long as some candidates are marked,
+// all of them are inserted in the line (quoted and separated with spaces) instead of
+// the current one only, so that they are all accepted at once.
+func (rl *Shell) menuToggleMark() {
+	rl.History.SkipSave()
+
+	// We don't do anything when not already completing.
+	if !rl.completer.IsActive() {
+		return
+	}
+
+	// Select the first candidate if none is selected yet.
+	if !rl.completer.IsInserting() {
+		rl.completer.Select(1, 0)
+	}
+
+	rl.completer.ToggleMark()
+	rl.completer.Select(1, 0)
+}
+
+// In a menu completion, scroll the preview pane of the
+// selected candidate (if any) up by one page.
+func (rl *Shell) menuPreviewScrollUp() {
+	rl.History.SkipSave()
+	rl.completer.ScrollPreview(-1)
+}
+
+// In a menu completion, scroll the preview pane of the
+// selected candidate (if any) down by one page.
+func (rl *Shell) menuPreviewScrollDown() {
+	rl.History.SkipSave()
+	rl.completer.ScrollPreview(1)
+}
+
 // Open a completion menu (similar to menu-complete) with all currently populated Vim registers.
 func (rl *Shell) viRegistersComplete() {
 	rl.History.SkipSave()
@@ -194,25 +246,76 @@ func (rl *Shell) menuIncrementalSearch() {
 // Utilities --------------------------------------------------------------------------
 //
 
-// startMenuComplete generates a completion menu with completions
-// generated from a given completer, without selecting a candidate.
+// startMenuComplete generates a completion menu with completions generated from
+// a given completer, without selecting a candidate, and rings if there are none.
 func (rl *Shell) startMenuComplete(completer completion.Completer) {
+	if !rl.generateMenu(completer) {
+		rl.Bell.Ring()
+	}
+}
+
+// generateMenu generates a completion menu with completions generated from a given
+// completer, without selecting a candidate. It returns false if there are none.
+func (rl *Shell) generateMenu(completer completion.Completer) (found bool) {
 	rl.History.SkipSave()
 
 	rl.Keymap.SetLocal(keymap.MenuSelect)
-	rl.completer.GenerateWith(completer)
+
+	return rl.completer.GenerateWith(completer)
+}
+
+// completeCommonPrefix inserts the only completion candidate or the longest
+// common prefix of all candidates, and lists candidates when it is ambiguous,
+// either immediately or on the next attempt, without entering menu-select.
+func (rl *Shell) completeCommonPrefix() {
+	// A second attempt on the same ambiguous word lists candidates.
+	if rl.completer.Ambiguous() {
+		rl.completer.GenerateWith(rl.commandCompletion)
+		return
+	}
+
+	matches, modified := rl.completer.CompleteCommonPrefix(rl.commandCompletion)
+
+	switch {
+	case matches == 0:
+		rl.Bell.Ring()
+	case matches == 1:
+		return
+	case rl.Config.GetBool("show-all-if-ambiguous"),
+		rl.Config.GetBool("show-all-if-unmodified") && !modified:
+		rl.completer.GenerateWith(rl.commandCompletion)
+	default:
+		rl.Bell.Ring()
+	}
 }
 
 // commandCompletion generates the completions for commands/args/flags.
 func (rl *Shell) commandCompletion() completion.Values {
-	if rl.Completer == nil {
+	line, cursor := rl.completer.Line()
+	ctx := newCompletionContext(*line, cursor.Pos())
+
+	switch {
+	case rl.ContextCompleter != nil:
+		comps := rl.ContextCompleter(ctx)
+		comps = rl.applyMiddlewares(ctx, comps.resolve(rl.completer.Results()))
+
+		return comps.quote(comps.convert(), ctx)
+
+	case rl.Completer != nil:
+		comps := rl.Completer(*line, cursor.Pos())
+		comps = rl.applyMiddlewares(ctx, comps.resolve(rl.completer.Results()))
+
+		return comps.convert()
+
+	default:
 		return completion.Values{}
 	}
+}
 
-	line, cursor := rl.completer.Line()
-	comps := rl.Completer(*line, cursor.Pos())
-
-	return comps.convert()
+// InvalidateCompletions drops the completions cached under the given keys
+// (see Completions.Cache), or all cached completions if no keys are given.
+func (rl *Shell) InvalidateCompletions(keys ...string) {
+	rl.completer.Results().Invalidate(keys...)
 }
 
 // historyCompletion manages the various completion/isearch modes related
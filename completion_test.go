@@ -0,0 +1,196 @@
+package readline
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/reeflective/readline/internal/completion"
+)
+
+func TestMenuToggleMarkContext(t *testing.T) {
+	rl := NewShell()
+	rl.ContextCompleter = func(ctx CompletionContext) Completions {
+		return CompleteValues("my file.go", "my fine.go", "other.go")
+	}
+
+	rl.line.Set([]rune(`ls my\ fi`)...)
+	rl.cursor.Set(rl.line.Len())
+
+	rl.possibleCompletions()
+	rl.menuToggleMark()
+	rl.menuToggleMark()
+
+	line, _, _ := rl.completer.GetBuffer()
+
+	if want := `ls my\ file.go my\ fine.go`; string(*line) != want {
+		t.Errorf("menu-toggle-mark line = %q, want %q", string(*line), want)
+	}
+}
+
+func TestCompletionsMergeDeferred(t *testing.T) {
+	var calls int
+
+	branches := CompleteFunc(func() Completions {
+		calls++
+		return CompleteValues("main", "dev")
+	}).Cache("branches", 0)
+
+	rl := NewShell()
+	rl.Completer = func(line []rune, cursor int) Completions {
+		return CompleteValues("--force", "main").Merge(branches)
+	}
+
+	rl.line.Set([]rune("git push ")...)
+	rl.cursor.Set(rl.line.Len())
+
+	for range 2 {
+		rl.completer.ResetForce()
+		rl.possibleCompletions()
+
+		if matches := rl.completer.Matches(); matches != 3 {
+			t.Errorf("merged completions matches = %d, want 3", matches)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("merged completions generated %d times, want 1 (cached)", calls)
+	}
+}
+
+func TestCompletionsMergeSettings(t *testing.T) {
+	tests := []struct {
+		name  string
+		other Completions
+		check func(values completion.Values) bool
+	}{
+		{
+			name:  "Expanded descriptions",
+			other: CompleteValues("b").ExpandSelectedDescription(),
+			check: func(values completion.Values) bool { return values.ExpandDesc["*"] },
+		},
+		{
+			name:  "Tree display",
+			other: CompleteValues("b/c").DisplayTree("/"),
+			check: func(values completion.Values) bool { return values.Tree["*"] == "/" },
+		},
+		{
+			name:  "Listed and unsorted values",
+			other: CompleteValues("b").DisplayList().NoSort().ListSeparator("::"),
+			check: func(values completion.Values) bool {
+				return values.ListLong["*"] && values.NoSort["*"] && values.ListSep["*"] == "::"
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged := CompleteValues("a").Merge(test.other)
+
+			if len(merged.values) != 2 || !test.check(merged.convert()) {
+				t.Errorf("Merge() = %+v, want both values and the settings of the merged completions", merged)
+			}
+		})
+	}
+}
+
+func TestCompletionsMergeDeferredSettings(t *testing.T) {
+	branches := CompleteFunc(func() Completions {
+		return CompleteValues("main", "dev").Usage("branches")
+	})
+
+	comps := CompleteValues("main", "--force").Merge(branches).
+		Tag("refs").
+		Usage("push refs").
+		DisplayList().
+		FilterF(func(comp Completion) bool { return comp.Value != "dev" })
+
+	resolved := comps.resolve(completion.NewCache())
+
+	var values []string
+
+	for _, val := range resolved.values {
+		values = append(values, val.Value)
+
+		if val.Tag != "refs" {
+			t.Errorf("merged completion %q tag = %q, want %q", val.Value, val.Tag, "refs")
+		}
+	}
+
+	if want := []string{"main", "--force"}; !slices.Equal(values, want) {
+		t.Errorf("merged completions = %q, want %q", values, want)
+	}
+
+	if resolved.usage != "push refs" || !resolved.listLong["*"] {
+		t.Errorf("merged completions settings = %+v, want the ones of the merge", resolved)
+	}
+}
+
+func TestCompletionsMergeMiddlewares(t *testing.T) {
+	var calls int
+
+	rl := NewShell()
+	rl.Completer = func(line []rune, cursor int) Completions {
+		return CompleteValues("a", "b").Merge(CompleteFunc(func() Completions {
+			return CompleteValues("c", "d")
+		}))
+	}
+
+	rl.UseCompletionMiddleware(func(ctx CompletionContext, comps Completions) Completions {
+		calls++
+		comps.values = comps.values[:min(len(comps.values), 3)]
+
+		return comps
+	})
+
+	rl.line.Set([]rune("cmd ")...)
+	rl.cursor.Set(rl.line.Len())
+
+	if values := rl.commandCompletion(); len(values.Raw()) != 3 || calls != 1 {
+		t.Errorf("merged completions = %d values (%d middleware calls), want 3 (1 call)", len(values.Raw()), calls)
+	}
+}
+
+func TestMenuAcceptMarked(t *testing.T) {
+	var accepted []string
+
+	accept := func(value string) AcceptFunc {
+		return func(line []rune, cursor int) ([]rune, int, bool) {
+			accepted = append(accepted, value)
+			return line, cursor, false
+		}
+	}
+
+	rankings := filepath.Join(t.TempDir(), "rankings")
+
+	rl := NewShell()
+	rl.Config.Set("completion-frecency-file", rankings)
+	rl.ContextCompleter = func(ctx CompletionContext) Completions {
+		return CompleteRaw([]Completion{
+			{Value: "alpha", Display: "alpha", Accept: accept("alpha")},
+			{Value: "beta", Display: "beta", Accept: accept("beta")},
+			{Value: "gamma", Display: "gamma", Accept: accept("gamma")},
+		})
+	}
+
+	line, _ := readKeys(t, rl, "ls \t\t\x14\x14\r\r")
+
+	if want := "ls beta gamma"; line != want {
+		t.Errorf("Readline() = %q, want %q", line, want)
+	}
+
+	if want := []string{"beta", "gamma"}; !slices.Equal(accepted, want) {
+		t.Errorf("accept actions run for %q, want %q", accepted, want)
+	}
+
+	saved, err := os.ReadFile(rankings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(saved), "\tbeta\t") || !strings.Contains(string(saved), "\tgamma\t") {
+		t.Errorf("completion rankings = %q, want both marked candidates", saved)
+	}
+}
@@ -2,27 +2,115 @@ package readline
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/reeflective/readline/internal/completion"
+	"github.com/reeflective/readline/internal/strutil"
 )
 
 // Completion represents a completion candidate.
 type Completion = completion.Candidate
 
+// AcceptFunc is called when a completion candidate having it is accepted into the
+// input line. It can rewrite the line and move the cursor, for instance to insert a
+// snippet and place the cursor inside it, and ask for a follow-up completion:
+//
+//	Completion{Value: "--message=\"\"", Accept: func(line []rune, cursor int) ([]rune, int, bool) {
+//		return line, cursor - 1, false // Place the cursor between the quotes.
+//	}}
+type AcceptFunc = completion.AcceptFunc
+
+// Word is a shell word of the input line, with its position and quoting state.
+type Word = strutil.Word
+
+// CompletionContext is the input line as split into shell words, along with
+// the word under the cursor and its quoting state. It is passed to the shell
+// ContextCompleter, so that the latter does not have to split the line itself.
+type CompletionContext struct {
+	Line    []rune // The input line.
+	Cursor  int    // The cursor position in the line.
+	Words   []Word // All words in the line, including an empty one under the cursor if it is not on a word.
+	Current int    // The index of the word under the cursor.
+
+	// The raw part of the current word, from its beginning up to the
+	// cursor, and from the cursor up to its end, with quotes and escapes.
+	Prefix string
+	Suffix string
+
+	// The part of the current word up to the cursor,
+	// with its quotes and escapes removed.
+	PrefixValue string
+
+	Quote    rune // The quote open at the cursor position, if any.
+	Unclosed bool // The cursor is inside an unclosed quote.
+}
+
+// newCompletionContext splits the line into shell words
+// and finds the word under the cursor and its quoting state.
+func newCompletionContext(line []rune, cursor int) CompletionContext {
+	ctx := CompletionContext{
+		Line:   line,
+		Cursor: cursor,
+		Words:  strutil.Tokenize(line),
+	}
+
+	ctx.Current = len(ctx.Words)
+
+	for i, word := range ctx.Words {
+		if word.End >= cursor {
+			ctx.Current = i
+			break
+		}
+	}
+
+	// The cursor is not on a word: insert an empty one.
+	if ctx.Current == len(ctx.Words) || ctx.Words[ctx.Current].Start > cursor {
+		ctx.Words = slices.Insert(ctx.Words, ctx.Current, Word{Start: cursor, End: cursor})
+	}
+
+	word := ctx.Words[ctx.Current]
+	ctx.Prefix = string(line[word.Start:cursor])
+	ctx.Suffix = string(line[cursor:word.End])
+
+	// The quoting state at the cursor is the one
+	// at the end of the word prefix, if any.
+	if prefix := strutil.Tokenize(line[word.Start:cursor]); len(prefix) > 0 {
+		ctx.PrefixValue = prefix[0].Value
+		ctx.Unclosed = prefix[0].Unclosed
+
+		if ctx.Unclosed {
+			ctx.Quote = prefix[0].Quote
+		}
+	}
+
+	return ctx
+}
+
 // Completions holds all completions candidates and their associated data,
 // including usage strings, messages, and suffix matchers for autoremoval.
 // Some of those additional settings will apply to all contained candidates,
 // except when these candidates have their own corresponding settings.
 type Completions struct {
-	values   completion.RawValues
-	messages completion.Messages
-	noSpace  completion.SuffixMatcher
-	usage    string
-	listLong map[string]bool
-	noSort   map[string]bool
-	listSep  map[string]string
-	pad      map[string]bool
-	escapes  map[string]bool
+	values     completion.RawValues
+	messages   completion.Messages
+	noSpace    completion.SuffixMatcher
+	usage      string
+	listLong   map[string]bool
+	horizontal map[string]bool
+	noSort     map[string]bool
+	listSep    map[string]string
+	pad        map[string]bool
+	escapes    map[string]bool
+	expandDesc map[string]bool
+	tree       map[string]string
+	preview    func(c Completion) string
+	generate   func() Completions
+	cacheKey   string
+	cacheTTL   time.Duration
+	merged     []Completions
+	apply      []func(comps Completions) Completions
 
 	// Initially this will be set to the part of the current word
 	// from the beginning of the word up to the position of the cursor.
@@ -125,6 +213,18 @@ func CompleteRaw(values []Completion) Completions {
 	return Completions{values: completion.RawValues(values)}
 }
 
+// CompleteFunc defers the generation of completions until the completion engine
+// actually needs them. This is mostly useful along with Completions.Cache, so that
+// expensive completers are only called when no valid cached results are found:
+//
+//	CompleteFunc(listRemoteResources).Cache("resources", time.Minute)
+//
+// Settings (tags, styles, etc) applied to the deferred completions themselves
+// are applied to the generated ones, taking precedence over their own settings.
+func CompleteFunc(generate func() Completions) Completions {
+	return Completions{generate: generate}
+}
+
 // Message displays a help messages in places where no completions can be generated.
 func Message(msg string, args ...any) Completions {
 	comps := Completions{}
@@ -138,8 +238,53 @@ func Message(msg string, args ...any) Completions {
 	return comps
 }
 
+// CompletionMessage is a completion message, with its level and tag, if any.
+type CompletionMessage = completion.Message
+
+// MessageLevel is the severity of a completion message, which determines
+// its style (the completion-*-style options).
+type MessageLevel = completion.MessageLevel
+
+// Completion message levels.
+const (
+	MessageInfo    = completion.MessageInfo
+	MessageUsage   = completion.MessageUsage
+	MessageWarning = completion.MessageWarning
+	MessageError   = completion.MessageError
+)
+
+// AddMessage adds a message with a given level. If a tag is given, the message
+// is displayed below the heading of the completion group with this tag, instead
+// of being displayed with other messages below the input line.
+//
+//	CompleteValues("origin", "upstream").Tag("remotes").
+//		AddMessage(MessageWarning, "remotes", "remote %s is unreachable", "upstream")
+func (c Completions) AddMessage(level MessageLevel, tag, msg string, args ...any) Completions {
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+
+	c.messages.AddMessage(completion.Message{Text: msg, Level: level, Tag: tag})
+
+	return c
+}
+
+// Error adds an error message, displayed below the input line.
+func (c Completions) Error(msg string, args ...any) Completions {
+	return c.AddMessage(MessageError, "", msg, args...)
+}
+
+// Warning adds a warning message, displayed below the input line.
+func (c Completions) Warning(msg string, args ...any) Completions {
+	return c.AddMessage(MessageWarning, "", msg, args...)
+}
+
 // Suppress suppresses specific error messages using regular expressions.
 func (c Completions) Suppress(expr ...string) Completions {
+	if c.later(func(comps Completions) Completions { return comps.Suppress(expr...) }) {
+		return c
+	}
+
 	if err := c.messages.Suppress(expr...); err != nil {
 		return CompleteMessage(err.Error())
 	}
@@ -166,6 +311,10 @@ func (c Completions) NoSpace(suffixes ...rune) Completions {
 //	a := CompleteValues("melon", "drop", "fall").Invoke(c)
 //	b := a.Prefix("water") // ["watermelon", "waterdrop", "waterfall"] but display still ["melon", "drop", "fall"]
 func (c Completions) Prefix(prefix string) Completions {
+	if c.later(func(comps Completions) Completions { return comps.Prefix(prefix) }) {
+		return c
+	}
+
 	for index, val := range c.values {
 		c.values[index].Value = prefix + val.Value
 	}
@@ -178,6 +327,10 @@ func (c Completions) Prefix(prefix string) Completions {
 //	a := CompleteValues("apple", "melon", "orange").Invoke(c)
 //	b := a.Suffix("juice") // ["applejuice", "melonjuice", "orangejuice"] but display still ["apple", "melon", "orange"]
 func (c Completions) Suffix(suffix string) Completions {
+	if c.later(func(comps Completions) Completions { return comps.Suffix(suffix) }) {
+		return c
+	}
+
 	for index, val := range c.values {
 		c.values[index].Value = val.Value + suffix
 	}
@@ -228,6 +381,10 @@ func (c Completions) StyleR(style *string) Completions {
 //	CompleteValues("dir/", "test.txt").StyleF(myStyleFunc)
 //	CompleteValues("true", "false").StyleF(styleForKeyword)
 func (c Completions) StyleF(f func(s string) string) Completions {
+	if c.later(func(comps Completions) Completions { return comps.StyleF(f) }) {
+		return c
+	}
+
 	for index, v := range c.values {
 		c.values[index].Style = f(v.Value)
 	}
@@ -250,6 +407,10 @@ func (c Completions) Tag(tag string) Completions {
 //		return "interfaces"
 //	})
 func (c Completions) TagF(f func(value string) string) Completions {
+	if c.later(func(comps Completions) Completions { return comps.TagF(f) }) {
+		return c
+	}
+
 	for index, v := range c.values {
 		c.values[index].Tag = f(v.Value)
 	}
@@ -276,6 +437,43 @@ func (c Completions) DisplayList(tags ...string) Completions {
 	return c
 }
 
+// DisplayHorizontal forces the completions grid to be filled row by row (row-major),
+// regardless of the print-completions-horizontally option. A series of tags can be
+// passed to restrict this to these tags. If empty, will be applied to all completions.
+func (c Completions) DisplayHorizontal(tags ...string) Completions {
+	return c.setLayout(true, tags...)
+}
+
+// DisplayVertical forces the completions grid to be filled column by column (column-major),
+// regardless of the print-completions-horizontally option. A series of tags can be passed
+// to restrict this to these tags. If empty, will be applied to all completions.
+func (c Completions) DisplayVertical(tags ...string) Completions {
+	return c.setLayout(false, tags...)
+}
+
+// DisplayTree displays path-like completions (a/b/c, dotted keys, etc) as a tree of their
+// segments, split with the given separator: path prefixes are nodes that can be expanded
+// and collapsed with the right and left arrow keys, and the full path is always inserted.
+// A series of tags can be passed to restrict this to these tags. If empty, will be applied
+// to all completions.
+//
+//	CompleteValues("core.editor", "core.pager", "user.name").DisplayTree(".")
+func (c Completions) DisplayTree(separator string, tags ...string) Completions {
+	if c.tree == nil {
+		c.tree = make(map[string]string)
+	}
+
+	if len(tags) == 0 {
+		c.tree["*"] = separator
+	}
+
+	for _, tag := range tags {
+		c.tree[tag] = separator
+	}
+
+	return c
+}
+
 // ListSeparator accepts a custom separator to use between the candidates and their descriptions.
 // If more than one separator is given, the list is considered to be a map of tag:separators, in
 // which case it will fail if the list has an odd number of values.
@@ -333,6 +531,10 @@ func (c Completions) NoSort(tags ...string) Completions {
 //	a := CompleteValues("A", "B", "C").Invoke(c)
 //	b := a.Filter([]string{"B"}) // ["A", "C"]
 func (c Completions) Filter(values []string) Completions {
+	if c.later(func(comps Completions) Completions { return comps.Filter(values) }) {
+		return c
+	}
+
 	c.values = c.values.Filter(values...)
 	return c
 }
@@ -384,12 +586,156 @@ func (c Completions) PreserveEscapes(tags ...string) Completions {
 	return c
 }
 
+// ExpandSelectedDescription only shows the full description of the selected
+// candidate, wrapped on as many rows as needed, while other descriptions are
+// cut to fit on their candidate row. This only applies to listed candidates.
+// If no arguments are given, this will apply to all tags.
+func (c Completions) ExpandSelectedDescription(tags ...string) Completions {
+	if c.expandDesc == nil {
+		c.expandDesc = make(map[string]bool)
+	}
+
+	if len(tags) == 0 {
+		c.expandDesc["*"] = true
+	}
+
+	for _, tag := range tags {
+		c.expandDesc[tag] = true
+	}
+
+	return c
+}
+
+// Preview sets a function producing a preview of the currently selected candidate,
+// displayed in a pane below the completions menu, or on its right if the option
+// completion-preview-position is "right". The preview can span multiple lines and
+// include color sequences, and can be scrolled with the menu-preview-scroll-* commands.
+//
+// The function runs in the background, so that a slow preview does not block the shell,
+// and its output is reused for each candidate until completions are generated again.
+//
+//	CompleteValues("main.go", "go.mod").Preview(func(c Completion) string {
+//		return fileHead(c.Value, 20)
+//	})
+func (c Completions) Preview(preview func(c Completion) string) Completions {
+	c.preview = preview
+	return c
+}
+
+// Cache stores the completions under a key (for instance a command path and the
+// index of the argument being completed), for a given time-to-live. A zero TTL
+// means the completions never expire until invalidated with Shell.InvalidateCompletions.
+//
+// When the completions were created with CompleteFunc, cached completions are used
+// without calling the generating function. Stale completions are still used, while
+// being regenerated in the background: the generating function must thus be safe
+// to call concurrently with the shell.
+func (c Completions) Cache(key string, ttl time.Duration) Completions {
+	c.cacheKey = key
+	c.cacheTTL = ttl
+
+	return c
+}
+
+// FilterF keeps only the values for which the function returns true.
+//
+//	CompleteValues("build", "__debug").FilterF(func(c Completion) bool {
+//		return !strings.HasPrefix(c.Value, "__")
+//	})
+func (c Completions) FilterF(keep func(comp Completion) bool) Completions {
+	if c.later(func(comps Completions) Completions { return comps.FilterF(keep) }) {
+		return c
+	}
+
+	values := make(completion.RawValues, 0, len(c.values))
+
+	for _, val := range c.values {
+		if keep(val) {
+			values = append(values, val)
+		}
+	}
+
+	c.values = values
+
+	return c
+}
+
+// SortF sorts values with the given function, keeping the order of equal values,
+// and disables the alphabetical sorting of the tags of these values accordingly.
+func (c Completions) SortF(less func(a, b Completion) bool) Completions {
+	if c.later(func(comps Completions) Completions { return comps.SortF(less) }) {
+		return c
+	}
+
+	c.values = slices.Clone(c.values)
+
+	slices.SortStableFunc(c.values, func(a, b Completion) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	var tags []string
+
+	for _, val := range c.values {
+		if !slices.Contains(tags, val.Tag) {
+			tags = append(tags, val.Tag)
+		}
+	}
+
+	if c.noSort == nil {
+		c.noSort = make(map[string]bool)
+	}
+
+	for _, tag := range tags {
+		c.noSort[tag] = true
+	}
+
+	return c
+}
+
+// EachMessage runs a function on each message, replacing it with the returned
+// one, or dropping it if the function returns false.
+func (c Completions) EachMessage(rewrite func(msg CompletionMessage) (CompletionMessage, bool)) Completions {
+	if c.later(func(comps Completions) Completions { return comps.EachMessage(rewrite) }) {
+		return c
+	}
+
+	var messages completion.Messages
+
+	for _, msg := range c.messages.All() {
+		if msg, keep := rewrite(msg); keep {
+			messages.AddMessage(msg)
+		}
+	}
+
+	c.messages = messages
+
+	return c
+}
+
 // Merge merges Completions (existing values are overwritten)
 //
 //	a := CompleteValues("A", "B").Invoke(c)
 //	b := CompleteValues("B", "C").Invoke(c)
 //	c := a.Merge(b) // ["A", "B", "C"]
+//
+// When some of the completions are deferred (CompleteFunc) or cached (Cache), all of
+// them are only merged once generated, each of them being cached under its own key.
+// Like with CompleteFunc, settings applied to the merged completions then apply to
+// the result of the merge.
 func (c Completions) Merge(others ...Completions) Completions {
+	all := append([]Completions{c}, others...)
+
+	if slices.ContainsFunc(all, Completions.deferred) {
+		return Completions{merged: all, PREFIX: c.PREFIX, SUFFIX: c.SUFFIX}
+	}
+
 	uniqueRawValues := make(map[string]Completion)
 
 	for _, other := range append([]Completions{c}, others...) {
@@ -414,42 +760,221 @@ func (c Completions) Merge(others ...Completions) Completions {
 
 // EachValue runs a function on each value, overwriting with the returned one.
 func (c *Completions) EachValue(tagF func(comp Completion) Completion) {
+	each := func(comps Completions) Completions {
+		comps.EachValue(tagF)
+		return comps
+	}
+
+	if c.later(each) {
+		return
+	}
+
 	for index, v := range c.values {
 		c.values[index] = tagF(v)
 	}
 }
 
+// splitTag returns the completions with only the values having a tag,
+// and the other values.
+func (c Completions) splitTag(tag string) (tagged Completions, others completion.RawValues) {
+	tagged = c
+	tagged.values = nil
+
+	for _, val := range c.values {
+		if val.Tag == tag {
+			tagged.values = append(tagged.values, val)
+		} else {
+			others = append(others, val)
+		}
+	}
+
+	return tagged, others
+}
+
 func (c *Completions) merge(other Completions) {
 	if other.usage != "" {
 		c.usage = other.usage
 	}
 
+	if c.preview == nil {
+		c.preview = other.preview
+	}
+
 	c.noSpace.Merge(other.noSpace)
 	c.messages.Merge(other.messages)
 
-	for tag := range other.listLong {
-		if _, found := c.listLong[tag]; !found {
-			c.listLong[tag] = true
-		}
+	c.listLong = completion.MergeTags(c.listLong, other.listLong)
+	c.horizontal = completion.MergeTags(c.horizontal, other.horizontal)
+	c.noSort = completion.MergeTags(c.noSort, other.noSort)
+	c.listSep = completion.MergeTags(c.listSep, other.listSep)
+	c.pad = completion.MergeTags(c.pad, other.pad)
+	c.escapes = completion.MergeTags(c.escapes, other.escapes)
+	c.tree = completion.MergeTags(c.tree, other.tree)
+	c.expandDesc = completion.MergeTags(c.expandDesc, other.expandDesc)
+}
+
+func (c *Completions) setLayout(horizontal bool, tags ...string) Completions {
+	if c.horizontal == nil {
+		c.horizontal = make(map[string]bool)
 	}
 
-	for tag := range other.noSort {
-		if _, found := c.noSort[tag]; !found {
-			c.noSort[tag] = true
-		}
+	if len(tags) == 0 {
+		c.horizontal["*"] = horizontal
 	}
 
-	for tag := range other.listSep {
-		if _, found := c.listSep[tag]; !found {
-			c.listSep[tag] = other.listSep[tag]
-		}
+	for _, tag := range tags {
+		c.horizontal[tag] = horizontal
 	}
 
-	for tag := range other.pad {
-		if _, found := c.pad[tag]; !found {
-			c.pad[tag] = other.pad[tag]
-		}
+	return *c
+}
+
+// resolve returns the completions to use, either cached ones if the completions have
+// a cache key, or by generating them if they are deferred, refreshing the cache when
+// needed. The functions deferred until then (tags, styles, filters...) are applied
+// to the resolved completions, and the PREFIX/SUFFIX settings always apply to cached
+// completions.
+func (c *Completions) resolve(cache *completion.Cache) Completions {
+	var comps Completions
+
+	if c.cacheKey != "" {
+		comps = fromValues(c.cached(cache))
+	} else {
+		comps = c.generated(cache)
 	}
+
+	for _, apply := range c.apply {
+		comps = apply(comps)
+	}
+
+	return comps
+}
+
+// cached returns the completions cached under their key, generating and caching
+// them first if needed, or refreshing them in the background when they are stale.
+func (c *Completions) cached(cache *completion.Cache) completion.Values {
+	values, fresh, found := cache.Get(c.cacheKey)
+
+	switch {
+	case !found || (c.generate == nil && len(c.merged) == 0):
+		comps := c.generated(cache)
+		values = comps.convert()
+		cache.Set(c.cacheKey, c.cacheTTL, values)
+	case !fresh:
+		cache.Refresh(c.cacheKey, c.cacheTTL, func() completion.Values {
+			comps := c.generated(cache)
+			return comps.convert()
+		})
+	}
+
+	values.PREFIX = c.PREFIX
+	values.SUFFIX = c.SUFFIX
+
+	return values
+}
+
+// generated returns the completions generated by the deferred function, or merged
+// from several ones, with the settings of the deferred completions applied to them.
+// The completions are returned as is when they are not deferred.
+func (c *Completions) generated(cache *completion.Cache) Completions {
+	var generated Completions
+
+	switch {
+	case len(c.merged) > 0:
+		generated = c.resolveMerged(cache)
+	case c.generate != nil:
+		comps := c.generate()
+		generated = comps.resolve(cache)
+	default:
+		comps := *c
+		comps.cacheKey, comps.cacheTTL, comps.apply = "", 0, nil
+
+		return comps
+	}
+
+	return c.settle(generated)
+}
+
+// resolveMerged resolves each of the merged completions, and merges the results.
+func (c *Completions) resolveMerged(cache *completion.Cache) Completions {
+	values := completion.AddRaw(nil)
+
+	for _, part := range c.merged {
+		comps := part.resolve(cache)
+		values.Merge(comps.convert())
+	}
+
+	return fromValues(values)
+}
+
+// settle returns the generated completions with the settings of the
+// deferred completions, which take precedence over generated ones.
+func (c *Completions) settle(generated Completions) Completions {
+	generated.noSpace.Merge(c.noSpace)
+	generated.messages.Merge(c.messages)
+
+	if c.usage != "" {
+		generated.usage = c.usage
+	}
+
+	if c.preview != nil {
+		generated.preview = c.preview
+	}
+
+	generated.listLong = overrideTags(generated.listLong, c.listLong)
+	generated.horizontal = overrideTags(generated.horizontal, c.horizontal)
+	generated.noSort = overrideTags(generated.noSort, c.noSort)
+	generated.listSep = overrideTags(generated.listSep, c.listSep)
+	generated.pad = overrideTags(generated.pad, c.pad)
+	generated.escapes = overrideTags(generated.escapes, c.escapes)
+	generated.expandDesc = overrideTags(generated.expandDesc, c.expandDesc)
+	generated.tree = overrideTags(generated.tree, c.tree)
+
+	if generated.PREFIX == "" {
+		generated.PREFIX = c.PREFIX
+	}
+
+	if generated.SUFFIX == "" {
+		generated.SUFFIX = c.SUFFIX
+	}
+
+	return generated
+}
+
+// deferred returns true if the completions are generated or cached when resolved.
+func (c Completions) deferred() bool {
+	return c.generate != nil || c.cacheKey != "" || len(c.merged) > 0
+}
+
+// later defers a function modifying the completions until they are resolved,
+// if they are deferred, in which case it returns true: their values and some
+// of their settings are not known before that.
+func (c *Completions) later(apply func(comps Completions) Completions) bool {
+	if !c.deferred() {
+		return false
+	}
+
+	c.apply = append(slices.Clip(c.apply), apply)
+
+	return true
+}
+
+// quote quotes all completion values for insertion in the current
+// word of the context, unless the PREFIX of the completions is set.
+func (c *Completions) quote(values completion.Values, ctx CompletionContext) completion.Values {
+	if c.PREFIX != "" {
+		return values
+	}
+
+	closeQuote := ctx.Unclosed && ctx.Words[ctx.Current].Unclosed
+	values = values.Quote(ctx.Prefix, ctx.PrefixValue, ctx.Quote, closeQuote)
+
+	values.PREFIX = ctx.Prefix
+	if values.SUFFIX == "" {
+		values.SUFFIX = ctx.Suffix
+	}
+
+	return values
 }
 
 func (c *Completions) convert() completion.Values {
@@ -459,13 +984,58 @@ func (c *Completions) convert() completion.Values {
 	comps.NoSpace = c.noSpace
 	comps.Usage = c.usage
 	comps.ListLong = c.listLong
+	comps.Horizontal = c.horizontal
 	comps.NoSort = c.noSort
 	comps.ListSep = c.listSep
 	comps.Pad = c.pad
 	comps.Escapes = c.escapes
+	comps.ExpandDesc = c.expandDesc
+	comps.Tree = c.tree
+	comps.Preview = c.preview
 
 	comps.PREFIX = c.PREFIX
 	comps.SUFFIX = c.SUFFIX
 
 	return comps
 }
+
+// fromValues returns completions holding a copy of the values and their settings,
+// so that they can be modified without altering the cached completions.
+func fromValues(values completion.Values) Completions {
+	comps := Completions{
+		values:     slices.Clone(values.Raw()),
+		noSpace:    values.NoSpace,
+		usage:      values.Usage,
+		listLong:   maps.Clone(values.ListLong),
+		horizontal: maps.Clone(values.Horizontal),
+		noSort:     maps.Clone(values.NoSort),
+		listSep:    maps.Clone(values.ListSep),
+		pad:        maps.Clone(values.Pad),
+		escapes:    maps.Clone(values.Escapes),
+		expandDesc: maps.Clone(values.ExpandDesc),
+		tree:       maps.Clone(values.Tree),
+		preview:    values.Preview,
+		PREFIX:     values.PREFIX,
+		SUFFIX:     values.SUFFIX,
+	}
+
+	comps.messages.Merge(values.Messages)
+
+	return comps
+}
+
+// overrideTags returns a copy of the per-tag settings, overridden by other ones.
+func overrideTags[T any](settings, other map[string]T) map[string]T {
+	if len(other) == 0 {
+		return settings
+	}
+
+	settings = maps.Clone(settings)
+	if settings == nil {
+		settings = make(map[string]T, len(other))
+	}
+
+	maps.Copy(settings, other)
+
+	return settings
+}
@@ -58,6 +58,7 @@ func (rl *Shell) standardCommands() commands {
 		"forward-backward-delete-char": rl.forwardBackwardDeleteChar,
 		"quoted-insert":                rl.quotedInsert,
 		"tab-insert":                   rl.tabInsert,
+		"newline-insert":               rl.newlineInsert,
 		"self-insert":                  rl.selfInsert,
 		"bracketed-paste-begin":        rl.bracketedPasteBegin,
 		"transpose-chars":              rl.transposeChars,
@@ -415,6 +416,13 @@ func (rl *Shell) tabInsert() {
 	rl.cursor.InsertAt('\t')
 }
 
+// Insert a newline character, without accepting the line.
+func (rl *Shell) newlineInsert() {
+	rl.History.SkipSave()
+
+	rl.cursor.InsertAt(inputrc.Newline)
+}
+
 // Insert the character typed.
 func (rl *Shell) selfInsert() {
 	rl.History.SkipSave()
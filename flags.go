@@ -0,0 +1,202 @@
+package readline
+
+import (
+	"flag"
+	"strings"
+)
+
+// FlagCompleter completes the command line of programs using the standard library
+// flag package: its subcommands, their -flag/--flag names described by their usage
+// strings, and the values of flags for which a completer has been registered.
+// Its Complete method is to be used as the shell ContextCompleter:
+//
+//	root := readline.NewFlagCompleter(rootFlags)
+//	root.AddCommand("build", "Build the project", buildFlags).
+//		CompleteFlag("target", completeTargets)
+//
+//	shell.ContextCompleter = root.Complete
+type FlagCompleter struct {
+	name        string
+	description string
+	flags       *flag.FlagSet
+	commands    []*FlagCompleter
+	values      map[string]func(ctx CompletionContext) Completions
+	args        func(ctx CompletionContext) Completions
+}
+
+// NewFlagCompleter returns a completer for a root command and its flags.
+// The flag set can be nil if the command has no flags.
+func NewFlagCompleter(flags *flag.FlagSet) *FlagCompleter {
+	return &FlagCompleter{
+		flags:  flags,
+		values: make(map[string]func(ctx CompletionContext) Completions),
+	}
+}
+
+// AddCommand adds a subcommand with its own flags, and returns its completer.
+func (c *FlagCompleter) AddCommand(name, description string, flags *flag.FlagSet) *FlagCompleter {
+	cmd := NewFlagCompleter(flags)
+	cmd.name = name
+	cmd.description = description
+	c.commands = append(c.commands, cmd)
+
+	return cmd
+}
+
+// CompleteFlag registers a completer for the values of a flag of the command.
+func (c *FlagCompleter) CompleteFlag(name string, values func(ctx CompletionContext) Completions) *FlagCompleter {
+	c.values[name] = values
+	return c
+}
+
+// CompleteArgs registers a completer for the positional arguments of the command.
+func (c *FlagCompleter) CompleteArgs(args func(ctx CompletionContext) Completions) *FlagCompleter {
+	c.args = args
+	return c
+}
+
+// Complete finds the command being used and whether the cursor is on a flag
+// name, a flag value or a positional argument, and completes it accordingly.
+func (c *FlagCompleter) Complete(ctx CompletionContext) Completions {
+	// The first word is the program itself.
+	if ctx.Current == 0 {
+		return Completions{}
+	}
+
+	cmd := c
+	args := 0
+	flagsDone := false
+
+	var pending *flag.Flag
+
+	for _, word := range ctx.Words[1:ctx.Current] {
+		switch {
+		case pending != nil:
+			pending = nil
+
+		case !flagsDone && word.Value == "--":
+			flagsDone = true
+
+		case !flagsDone && isFlag(word.Value):
+			name, _, hasValue := strings.Cut(strings.TrimLeft(word.Value, "-"), "=")
+			if fl := cmd.lookup(name); fl != nil && !hasValue && !isBoolFlag(fl) {
+				pending = fl
+			}
+
+		case args == 0 && cmd.command(word.Value) != nil:
+			cmd = cmd.command(word.Value)
+
+		default:
+			// Like the flag package, stop parsing flags at the first argument.
+			args++
+			flagsDone = true
+		}
+	}
+
+	prefix := ctx.PrefixValue
+
+	switch {
+	case pending != nil:
+		return cmd.completeValue(ctx, pending, "")
+
+	case !flagsDone && isFlag(prefix) && strings.Contains(prefix, "="):
+		name, _, _ := strings.Cut(prefix, "=")
+		if fl := cmd.lookup(strings.TrimLeft(name, "-")); fl != nil {
+			return cmd.completeValue(ctx, fl, name+"=")
+		}
+
+		return Completions{}
+
+	case !flagsDone && strings.HasPrefix(prefix, "-"):
+		return cmd.completeFlags(prefix)
+
+	default:
+		return cmd.completeArgs(ctx, args)
+	}
+}
+
+// completeFlags completes flag names, with as many dashes as already typed.
+func (c *FlagCompleter) completeFlags(prefix string) Completions {
+	if c.flags == nil {
+		return Completions{}
+	}
+
+	dashes := "-"
+	if strings.HasPrefix(prefix, "--") {
+		dashes = "--"
+	}
+
+	var flags []string
+
+	c.flags.VisitAll(func(fl *flag.Flag) {
+		_, usage := flag.UnquoteUsage(fl)
+		flags = append(flags, dashes+fl.Name, usage)
+	})
+
+	return CompleteValuesDescribed(flags...).Tag("flags")
+}
+
+// completeValue completes the value of a flag, prefixed with the flag name if the
+// value is in the same word (-flag=value): the value completer is passed a context
+// with the flag name trimmed from the word prefix, and the flag name is added to the
+// values once they are resolved, even if they are deferred or cached.
+func (c *FlagCompleter) completeValue(ctx CompletionContext, fl *flag.Flag, prefix string) Completions {
+	values, found := c.values[fl.Name]
+	if !found {
+		name, usage := flag.UnquoteUsage(fl)
+		return Completions{}.AddMessage(MessageUsage, "", "-%s %s: %s", fl.Name, name, usage)
+	}
+
+	if prefix != "" {
+		ctx.Prefix = strings.TrimPrefix(ctx.Prefix, prefix)
+		ctx.PrefixValue = strings.TrimPrefix(ctx.PrefixValue, prefix)
+	}
+
+	return values(ctx).Prefix(prefix)
+}
+
+// completeArgs completes subcommands (if no argument has been given yet) and arguments.
+func (c *FlagCompleter) completeArgs(ctx CompletionContext, args int) Completions {
+	var commands []string
+
+	if args == 0 {
+		for _, cmd := range c.commands {
+			commands = append(commands, cmd.name, cmd.description)
+		}
+	}
+
+	comps := CompleteValuesDescribed(commands...).Tag("commands")
+
+	if c.args != nil {
+		return c.args(ctx).Merge(comps)
+	}
+
+	return comps
+}
+
+func (c *FlagCompleter) lookup(name string) *flag.Flag {
+	if c.flags == nil {
+		return nil
+	}
+
+	return c.flags.Lookup(name)
+}
+
+func (c *FlagCompleter) command(name string) *FlagCompleter {
+	for _, cmd := range c.commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+func isFlag(word string) bool {
+	return len(word) > 1 && word[0] == '-'
+}
+
+func isBoolFlag(fl *flag.Flag) bool {
+	boolFlag, ok := fl.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
@@ -0,0 +1,155 @@
+package readline
+
+import (
+	"flag"
+	"slices"
+	"testing"
+
+	"github.com/reeflective/readline/internal/completion"
+)
+
+func newTestFlagCompleter(contexts *[]CompletionContext) *FlagCompleter {
+	rootFlags := flag.NewFlagSet("tool", flag.ContinueOnError)
+	rootFlags.Bool("v", false, "verbose output")
+	rootFlags.String("config", "", "config `file`")
+
+	buildFlags := flag.NewFlagSet("build", flag.ContinueOnError)
+	buildFlags.String("target", "", "target platform")
+	buildFlags.String("os", "", "target system")
+	buildFlags.Bool("race", false, "enable race detection")
+
+	root := NewFlagCompleter(rootFlags)
+	root.AddCommand("build", "Build the project", buildFlags).
+		CompleteFlag("target", func(ctx CompletionContext) Completions {
+			*contexts = append(*contexts, ctx)
+			return CompleteValues("linux", "darwin")
+		}).
+		CompleteFlag("os", func(ctx CompletionContext) Completions {
+			return CompleteFunc(func() Completions {
+				return CompleteValues("plan9")
+			}).Cache("os", 0)
+		}).
+		CompleteArgs(func(ctx CompletionContext) Completions {
+			return CompleteValues("./...")
+		})
+	root.AddCommand("test", "Test the project", nil)
+
+	return root
+}
+
+func TestFlagCompleter(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		want      []string
+		wantUsage bool
+	}{
+		{
+			name: "Program name",
+			line: "to",
+		},
+		{
+			name: "Subcommands",
+			line: "tool ",
+			want: []string{"build", "test"},
+		},
+		{
+			name: "Subcommands after root flags",
+			line: "tool -v ",
+			want: []string{"build", "test"},
+		},
+		{
+			name: "Flags with one dash",
+			line: "tool -",
+			want: []string{"-config", "-v"},
+		},
+		{
+			name: "Flags with two dashes",
+			line: "tool build --r",
+			want: []string{"--os", "--race", "--target"},
+		},
+		{
+			name: "Flag value in the same word",
+			line: "tool build -target=",
+			want: []string{"-target=linux", "-target=darwin"},
+		},
+		{
+			name: "Flag value in the next word",
+			line: "tool build --target ",
+			want: []string{"linux", "darwin"},
+		},
+		{
+			name: "Deferred flag value in the same word",
+			line: "tool build --os=",
+			want: []string{"--os=plan9"},
+		},
+		{
+			name: "Arguments after a bool flag",
+			line: "tool build -race ",
+			want: []string{"./..."},
+		},
+		{
+			name: "Arguments after a flag value",
+			line: "tool build -target linux ",
+			want: []string{"./..."},
+		},
+		{
+			name:      "Flag value without completer",
+			line:      "tool -config ",
+			wantUsage: true,
+		},
+		{
+			name: "Unknown flag with a value",
+			line: "tool -unknown=",
+		},
+		{
+			name: "Arguments after an unknown flag",
+			line: "tool build -unknown ",
+			want: []string{"./..."},
+		},
+		{
+			name: "No flags after arguments",
+			line: "tool build ./cmd -",
+			want: []string{"./..."},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var contexts []CompletionContext
+
+			ctx := newCompletionContext([]rune(test.line), len([]rune(test.line)))
+			comps := newTestFlagCompleter(&contexts).Complete(ctx)
+			values := comps.resolve(completion.NewCache())
+
+			var got []string
+			for _, val := range values.values {
+				got = append(got, val.Value)
+			}
+
+			slices.Sort(got)
+			want := slices.Sorted(slices.Values(test.want))
+
+			if !slices.Equal(got, want) {
+				t.Errorf("Complete(%q) = %q, want %q", test.line, got, want)
+			}
+
+			if usage := len(values.messages.All()) > 0; usage != test.wantUsage {
+				t.Errorf("Complete(%q) usage message = %t, want %t", test.line, usage, test.wantUsage)
+			}
+		})
+	}
+}
+
+func TestFlagCompleterValueContext(t *testing.T) {
+	var contexts []CompletionContext
+
+	line := "tool build --target=li"
+	ctx := newCompletionContext([]rune(line), len(line))
+
+	newTestFlagCompleter(&contexts).Complete(ctx)
+
+	if len(contexts) != 1 || contexts[0].Prefix != "li" || contexts[0].PrefixValue != "li" {
+		t.Errorf("Complete(%q) value completer context = %+v, want the value prefix only", line, contexts)
+	}
+}
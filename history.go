@@ -1,9 +1,11 @@
 package readline
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/reeflective/readline/inputrc"
+	"github.com/reeflective/readline/internal/color"
 	"github.com/reeflective/readline/internal/history"
 	"github.com/reeflective/readline/internal/strutil"
 )
@@ -301,7 +303,8 @@ func (rl *Shell) yankNthArg() {
 
 	// Abort if the required position is out of bounds.
 	argNth := rl.Iterations.Get()
-	if len(words) < argNth {
+	if argNth < 1 || len(words) < argNth {
+		rl.Bell.Ring()
 		return
 	}
 
@@ -650,33 +653,53 @@ func (rl *Shell) acceptLineWith(infer, hold bool) {
 	// Use the correct buffer for the rest of the function.
 	rl.line, rl.cursor, rl.selection = rl.completer.GetBuffer()
 
-	// Without multiline support, we always return the line.
-	if rl.AcceptMultiline == nil {
-		rl.Macros.StopRecord(rl.Keys.Caller()...)
+	// Without multiline support, we always return the line,
+	// otherwise ask the caller if it should be accepted as is.
+	if rl.AcceptMultiline != nil && !rl.AcceptMultiline(*rl.line) {
+		// If not, we should start editing another line,
+		// and insert a newline where our cursor value is.
+		// This has the nice advantage of being able to work
+		// in multiline mode even in the middle of the buffer.
+		rl.line.Insert(rl.cursor.Pos(), '\n')
+		rl.cursor.Inc()
 
-		rl.Display.AcceptLine()
-		rl.History.Accept(hold, infer, nil)
+		return
+	}
 
+	// Lines with errors must be accepted twice.
+	if rl.refuseLine() {
 		return
 	}
 
-	// Ask the caller if the line should be accepted
-	// as is, save the command line and accept it.
-	if rl.AcceptMultiline(*rl.line) {
-		rl.Macros.StopRecord(rl.Keys.Caller()...)
+	// Save the command line and accept it.
+	rl.Macros.StopRecord(rl.Keys.Caller()...)
 
-		rl.Display.AcceptLine()
-		rl.History.Accept(hold, infer, nil)
+	rl.Display.AcceptLine()
+	rl.History.Accept(hold, infer, nil)
+}
 
-		return
+// refuseLine returns true if the validator found errors in the line, unless
+// the line has just been refused already, in which case it is accepted anyway.
+func (rl *Shell) refuseLine() bool {
+	var errs int
+
+	for _, diag := range rl.Display.Diagnostics(*rl.line) {
+		if diag.Severity == SeverityError {
+			errs++
+		}
+	}
+
+	if errs == 0 || (rl.refused != nil && *rl.refused == string(*rl.line)) {
+		return false
 	}
 
-	// If not, we should start editing another line,
-	// and insert a newline where our cursor value is.
-	// This has the nice advantage of being able to work
-	// in multiline mode even in the middle of the buffer.
-	rl.line.Insert(rl.cursor.Pos(), '\n')
-	rl.cursor.Inc()
+	line := string(*rl.line)
+	rl.refused = &line
+
+	rl.Hint.SetTemporary(color.UnquoteRC(rl.Config.GetString("diagnostic-error-style")) +
+		fmt.Sprintf("%d error(s) in line, accept again to ignore", errs) + color.Reset)
+
+	return true
 }
 
 func (rl *Shell) insertAutosuggestPartial(emacs bool) {
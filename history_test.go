@@ -0,0 +1,59 @@
+package readline
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAcceptLineRefused(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     string
+		wantLine string
+		refused  bool
+	}{
+		{
+			name:     "Line without errors",
+			keys:     "good\r",
+			wantLine: "good",
+		},
+		{
+			name:    "Line with errors refused",
+			keys:    "bad\r",
+			refused: true,
+		},
+		{
+			name:     "Line with errors accepted twice",
+			keys:     "bad\r\r",
+			wantLine: "bad",
+			refused:  true,
+		},
+		{
+			name:    "Line with errors changed after refusal",
+			keys:    "bad\rs\r",
+			refused: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rl := NewShell()
+			rl.Validator = func(line []rune) []Diagnostic {
+				if !strings.HasPrefix(string(line), "bad") {
+					return nil
+				}
+
+				return []Diagnostic{{Start: 0, End: 3, Severity: SeverityError, Message: "bad command"}}
+			}
+
+			line, output := readKeys(t, rl, test.keys)
+			if line != test.wantLine {
+				t.Errorf("Readline() = %q, want %q", line, test.wantLine)
+			}
+
+			if refused := strings.Contains(output, "accept again to ignore"); refused != test.refused {
+				t.Errorf("Readline() refusal hint printed = %t, want %t", refused, test.refused)
+			}
+		})
+	}
+}
@@ -77,9 +77,19 @@ func (cfg *Config) Get(name string) interface{} {
 	return cfg.Vars[name]
 }
 
-// Set satisfies the Handler interface.
+// Set satisfies the Handler interface. As with readline, setting
+// prefer-visible-bell also sets bell-style to visible or audible.
 func (cfg *Config) Set(name string, value interface{}) error {
 	cfg.Vars[name] = value
+
+	if visible, ok := value.(bool); ok && name == "prefer-visible-bell" {
+		if visible {
+			cfg.Vars["bell-style"] = "visible"
+		} else {
+			cfg.Vars["bell-style"] = "audible"
+		}
+	}
+
 	return nil
 }
 
@@ -148,6 +148,26 @@ func escape(s string, m map[rune]string) string {
 	return strings.Join(v, "")
 }
 
+// Extended key modifiers, as reported by terminals using the kitty
+// keyboard protocol or xterm's modifyOtherKeys.
+const (
+	ModShift = 1 << iota
+	ModAlt
+	ModCtrl
+	ModSuper
+)
+
+// ExtendedKey returns the sequence of a key code pressed with some modifiers, as
+// reported by terminals using the kitty keyboard protocol (CSI code;modifiers u).
+// Those sequences are written with the \k{} escape in binds: \k{C-S-a}, \k{S-Return}.
+func ExtendedKey(code rune, mods int) string {
+	if mods == 0 {
+		return fmt.Sprintf("\x1b[%du", code)
+	}
+
+	return fmt.Sprintf("\x1b[%d;%du", code, mods+1)
+}
+
 // Encontrol encodes a Control-c code.
 func Encontrol(c rune) rune {
 	return unicode.ToUpper(c) & Control
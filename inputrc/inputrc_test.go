@@ -407,3 +407,24 @@ func readTestdata(name string) ([]byte, error) {
 
 //go:embed testdata/*.inputrc
 var testdata embed.FS
+
+func TestUnescapeExtendedKey(t *testing.T) {
+	tests := []struct {
+		s, exp string
+	}{
+		{`\k{S-Return}`, "\x1b[13;2u"},
+		{`\k{C-i}`, "\x1b[105;5u"},
+		{`\k{C-S-a}`, "\x1b[97;6u"},
+		{`\k{M-C-Tab}`, "\x1b[9;7u"},
+		{`\k{C-A}`, "\x1b[97;6u"},
+		{`\k{Esc}`, "\x1b[27u"},
+		{`a\k{S-Space}b`, "a\x1b[32;2ub"},
+		{`\k{X-a}`, "k{X-a}"},
+		{`\k{}`, "k{}"},
+	}
+	for i, test := range tests {
+		if s, exp := Unescape(test.s), test.exp; s != exp {
+			t.Errorf("test %d expected %q==%q", i, exp, s)
+		}
+	}
+}
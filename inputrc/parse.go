@@ -676,6 +676,16 @@ func unescapeRunes(r []rune, i, end int) string {
 			case octDigit(char1): // \n octal
 				seq = append(seq, char1-'0')
 				i++
+			case char1 == 'k' && char2 == '{': // \k{C-S-x} extended key
+				if key, length := decodeExtendedKey(r, i+3, end); length > 0 {
+					seq = append(seq, []rune(key)...)
+					i += length + 2
+
+					continue
+				}
+
+				seq = append(seq, char1)
+				i++
 			case ((char1 == 'C' && char4 == 'M') || (char1 == 'M' && char4 == 'C')) && char2 == '-' && char3 == '\\' && char5 == '-':
 				// \C-\M- or \M-\C- control meta prefix
 				if c6 := grab(r, i+metaSeqLength, end); c6 != 0 {
@@ -713,6 +723,69 @@ func unescapeRunes(r []rune, i, end int) string {
 	return string(seq)
 }
 
+// decodeExtendedKey decodes the modifiers and key name of an extended key, enclosed
+// in braces (eg. "S-Return}"), and returns its sequence and the length of its name,
+// including the closing brace, or 0 if the name is invalid.
+func decodeExtendedKey(r []rune, i, end int) (string, int) {
+	start := i
+
+	for ; i < end && r[i] != '}'; i++ {
+	}
+
+	if i == end || i == start {
+		return "", 0
+	}
+
+	name := string(r[start:i])
+	mods := 0
+
+	for len(name) > 2 && name[1] == '-' {
+		switch name[0] {
+		case 'S':
+			mods |= ModShift
+		case 'A', 'M':
+			mods |= ModAlt
+		case 'C':
+			mods |= ModCtrl
+		case 'D':
+			mods |= ModSuper
+		default:
+			return "", 0
+		}
+
+		name = name[2:]
+	}
+
+	var code rune
+
+	switch strings.ToLower(name) {
+	case "return", "enter", "cr":
+		code = Return
+	case "tab":
+		code = Tab
+	case "escape", "esc":
+		code = Esc
+	case "backspace", "bs":
+		code = Delete
+	case "space", "spc":
+		code = Space
+	default:
+		if utf8.RuneCountInString(name) != 1 {
+			return "", 0
+		}
+
+		code, _ = utf8.DecodeRuneInString(name)
+
+		// Keys are reported unshifted, with the shift modifier.
+		if unicode.IsUpper(code) {
+			code = unicode.ToLower(code)
+			mods |= ModShift
+		}
+	}
+
+	return ExtendedKey(code, mods), i - start + 1
+}
+
 // octDigit returns true when r is 0-7.
 func octDigit(c rune) bool {
 	return '0' <= c && c <= '7'
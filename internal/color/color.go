@@ -1,10 +1,11 @@
 package color
 
 import (
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/reeflective/readline/internal/term"
 )
 
 // Base text effects.
@@ -13,6 +14,7 @@ var (
 	Bold       = "\x1b[1m"
 	Dim        = "\x1b[2m"
 	Underscore = "\x1b[4m"
+	Undercurl  = "\x1b[4:3m"
 	Blink      = "\x1b[5m"
 	Reverse    = "\x1b[7m"
 
@@ -79,9 +81,10 @@ const (
 	SGREnd   = "m"
 )
 
-// Fmt formats a color code as an ANSI escaped color sequence.
+// Fmt formats a color code as an ANSI escaped color sequence,
+// downgraded to the colors supported by the terminal if needed.
 func Fmt(color string) string {
-	return SGRStart + color + SGREnd
+	return Downgrade(SGRStart + color + SGREnd)
 }
 
 // Trim accepts a string including arbitrary escaped sequences at arbitrary
@@ -116,26 +119,21 @@ func Trim(input string, maxPrintableLength int) string {
 
 // UnquoteRC removes the `\e` escape used in readline .inputrc
 // configuration values and replaces it with the printable escape.
+// The colors are downgraded to those supported by the terminal.
 func UnquoteRC(color string) string {
 	color = strings.ReplaceAll(color, `\e`, "\x1b")
 
 	if unquoted, err := strconv.Unquote(color); err == nil {
-		return unquoted
+		return Downgrade(unquoted)
 	}
 
-	return color
+	return Downgrade(color)
 }
 
 // HasEffects returns true if colors and effects are supported
 // on the current terminal.
 func HasEffects() bool {
-	if term := os.Getenv("TERM"); term == "" {
-		return false
-	} else if term == "dumb" {
-		return false
-	}
-
-	return true
+	return term.Caps.Colors > term.ColorNone
 }
 
 // DisableEffects will disable all colors and effects.
@@ -145,6 +143,7 @@ func DisableEffects() {
 	Bold = ""
 	Dim = ""
 	Underscore = ""
+	Undercurl = ""
 	Blink = ""
 	BoldReset = ""
 	DimReset = ""
@@ -0,0 +1,229 @@
+package color
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/reeflective/readline/internal/term"
+)
+
+// SGR parameters introducing extended colors.
+const (
+	sgrFg        = "38"
+	sgrBg        = "48"
+	sgrUnderline = "58"
+	sgr256       = "5"
+	sgrRGB       = "2"
+)
+
+var rxSGR = regexp.MustCompile(`\x1b\[([0-9;:]*)m`)
+
+// palette16 are the RGB values of the 16 standard colors, as used by xterm.
+var palette16 = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels are the RGB values of the 6x6x6 color cube of the 256-color palette.
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// Downgrade rewrites the colors used in all SGR sequences of a string to their
+// nearest equivalent supported by the terminal: RGB colors are converted to the
+// 256-color palette, which is itself converted to the 16 standard colors. All
+// sequences are removed if the terminal does not support colors and effects.
+// Colors are kept as is when the color level of the terminal is only guessed.
+func Downgrade(str string) string {
+	if term.Caps.GuessedColors {
+		return str
+	}
+
+	return downgrade(str, term.Caps.Colors)
+}
+
+func downgrade(str string, level term.ColorLevel) string {
+	if level == term.ColorTrue || !strings.Contains(str, SGRStart) {
+		return str
+	}
+
+	return rxSGR.ReplaceAllStringFunc(str, func(seq string) string {
+		if level == term.ColorNone {
+			return ""
+		}
+
+		params := rxSGR.FindStringSubmatch(seq)[1]
+		if params == "" {
+			return seq
+		}
+
+		if params = downgradeParams(params, level); params == "" {
+			return ""
+		}
+
+		return SGRStart + params + SGREnd
+	})
+}
+
+// downgradeParams converts the extended colors of SGR parameters.
+func downgradeParams(params string, level term.ColorLevel) string {
+	args := strings.Split(params, ";")
+	downgraded := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		target, color := args[i], args[i+1:]
+
+		// Colors with colon-separated arguments (38:5:n and 38:2::r:g:b).
+		if sub := strings.Split(args[i], ":"); len(sub) > 1 {
+			target, color = sub[0], sub[1:]
+			if len(color) == 5 && color[0] == sgrRGB {
+				color = append(color[:1], color[2:]...)
+			}
+		}
+
+		if target != sgrFg && target != sgrBg && target != sgrUnderline {
+			downgraded = append(downgraded, args[i])
+			continue
+		}
+
+		index, used := colorIndex(color)
+		if used == 0 {
+			downgraded = append(downgraded, args[i])
+			continue
+		}
+
+		if target == args[i] {
+			i += used
+		}
+
+		if code := formatColor(target, index, level); code != "" {
+			downgraded = append(downgraded, code)
+		}
+	}
+
+	return strings.Join(downgraded, ";")
+}
+
+// colorIndex returns the 256-color palette index of an extended color,
+// along with the number of arguments used by the color (0 if invalid).
+func colorIndex(args []string) (index, used int) {
+	if len(args) < 2 {
+		return 0, 0
+	}
+
+	switch strings.TrimLeft(args[0], "0") {
+	case sgr256:
+		index, err := strconv.Atoi(args[1])
+		if err != nil || index < 0 || index > 255 {
+			return 0, 0
+		}
+
+		return index, 2
+
+	case sgrRGB:
+		if len(args) < 4 {
+			return 0, 0
+		}
+
+		var rgb [3]int
+
+		for i := range rgb {
+			value, err := strconv.Atoi(args[i+1])
+			if err != nil || value < 0 || value > 255 {
+				return 0, 0
+			}
+
+			rgb[i] = value
+		}
+
+		return rgbTo256(rgb), 4
+	}
+
+	return 0, 0
+}
+
+// formatColor returns the SGR parameters for a palette color, either as a 256-color
+// or as a 16-color one. Underline colors are not supported with 16 colors.
+func formatColor(target string, index int, level term.ColorLevel) string {
+	if level >= term.Color256 {
+		return target + ";" + sgr256 + ";" + strconv.Itoa(index)
+	}
+
+	if index > 15 {
+		index = nearest16(paletteRGB(index))
+	}
+
+	switch {
+	case target == sgrFg && index < 8:
+		return strconv.Itoa(30 + index)
+	case target == sgrFg:
+		return strconv.Itoa(90 + index - 8)
+	case target == sgrBg && index < 8:
+		return strconv.Itoa(40 + index)
+	case target == sgrBg:
+		return strconv.Itoa(100 + index - 8)
+	default:
+		return ""
+	}
+}
+
+// rgbTo256 returns the nearest color of the 256-color
+// palette, either in the color cube or the grayscale ramp.
+func rgbTo256(rgb [3]int) int {
+	var cube [3]int
+
+	for i, value := range rgb {
+		switch {
+		case value < 48:
+			cube[i] = 0
+		case value < 115:
+			cube[i] = 1
+		default:
+			cube[i] = (value - 35) / 40
+		}
+	}
+
+	cubeIndex := 16 + 36*cube[0] + 6*cube[1] + cube[2]
+
+	average := (rgb[0] + rgb[1] + rgb[2]) / 3
+	grayIndex := 232 + min(max(average-3, 0)/10, 23)
+
+	if distance(rgb, paletteRGB(grayIndex)) < distance(rgb, paletteRGB(cubeIndex)) {
+		return grayIndex
+	}
+
+	return cubeIndex
+}
+
+// nearest16 returns the nearest of the 16 standard colors.
+func nearest16(rgb [3]int) int {
+	nearest := 0
+
+	for index, color := range palette16 {
+		if distance(rgb, color) < distance(rgb, palette16[nearest]) {
+			nearest = index
+		}
+	}
+
+	return nearest
+}
+
+// paletteRGB returns the RGB value of a 256-color palette index.
+func paletteRGB(index int) [3]int {
+	switch {
+	case index < 16:
+		return palette16[index]
+	case index < 232:
+		index -= 16
+		return [3]int{cubeLevels[index/36], cubeLevels[index/6%6], cubeLevels[index%6]}
+	default:
+		gray := 8 + (index-232)*10
+		return [3]int{gray, gray, gray}
+	}
+}
+
+func distance(a, b [3]int) int {
+	red, green, blue := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return red*red + green*green + blue*blue
+}
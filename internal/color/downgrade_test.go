@@ -0,0 +1,55 @@
+package color
+
+import (
+	"testing"
+
+	"github.com/reeflective/readline/internal/term"
+)
+
+func TestDowngrade(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		level term.ColorLevel
+		want  string
+	}{
+		{name: "Truecolor unchanged", input: "\x1b[38;2;255;0;0mred", level: term.ColorTrue, want: "\x1b[38;2;255;0;0mred"},
+		{name: "RGB to 256", input: "\x1b[38;2;255;0;0mred", level: term.Color256, want: "\x1b[38;5;196mred"},
+		{name: "RGB gray to 256", input: "\x1b[48;2;128;128;128m", level: term.Color256, want: "\x1b[48;5;244m"},
+		{name: "RGB with colons", input: "\x1b[38:2::0:0:255m", level: term.Color256, want: "\x1b[38;5;21m"},
+		{name: "256 normalized", input: "\x1b[1;38;05;242m", level: term.Color256, want: "\x1b[1;38;5;242m"},
+		{name: "256 to 16", input: "\x1b[1;38;05;196m", level: term.Color16, want: "\x1b[1;91m"},
+		{name: "256 background to 16", input: "\x1b[48;5;231m", level: term.Color16, want: "\x1b[107m"},
+		{name: "Standard colors", input: "\x1b[38;5;4mblue", level: term.Color16, want: "\x1b[34mblue"},
+		{name: "Underline color removed", input: "\x1b[4:3;58;5;196m", level: term.Color16, want: "\x1b[4:3m"},
+		{name: "Empty sequence removed", input: "\x1b[58;2;255;0;0mx", level: term.Color16, want: "x"},
+		{name: "Effects kept", input: "\x1b[1;31mbold\x1b[0m", level: term.Color16, want: "\x1b[1;31mbold\x1b[0m"},
+		{name: "No colors", input: "\x1b[1;31mbold\x1b[0m", level: term.ColorNone, want: "bold"},
+		{name: "Invalid color", input: "\x1b[38;5;300m", level: term.Color16, want: "\x1b[38;5;300m"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := downgrade(test.input, test.level); got != test.want {
+				t.Errorf("downgrade() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDowngradeGuessedColors(t *testing.T) {
+	caps := term.Caps
+	defer func() { term.Caps = caps }()
+
+	term.Caps = term.Capabilities{Colors: term.Color16, GuessedColors: true}
+
+	if got := Downgrade("\x1b[38;2;255;0;0mred"); got != "\x1b[38;2;255;0;0mred" {
+		t.Errorf("Downgrade() with guessed colors = %q, want unchanged", got)
+	}
+
+	term.Caps.GuessedColors = false
+
+	if got := Downgrade("\x1b[38;5;196mred"); got != "\x1b[91mred" {
+		t.Errorf("Downgrade() with known colors = %q, want %q", got, "\x1b[91mred")
+	}
+}
@@ -0,0 +1,121 @@
+package completion
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache stores completion results under user-defined keys, each with its own
+// time-to-live. Stale results can still be used while they are regenerated in
+// the background, so that expensive completers can return instantly.
+type Cache struct {
+	entries map[string]*cacheEntry
+	mutex   sync.Mutex
+}
+
+type cacheEntry struct {
+	values     Values
+	stored     time.Time
+	ttl        time.Duration
+	refreshing bool
+}
+
+// NewCache returns an empty completion results cache.
+func NewCache() *Cache {
+	return &Cache{entries: make(map[string]*cacheEntry)}
+}
+
+// Get returns the completions cached under key, if any, and whether they are
+// still fresh, that is, not older than their TTL. A zero or negative TTL means
+// that the completions never expire until they are explicitly invalidated.
+func (c *Cache) Get(key string) (values Values, fresh, found bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, found := c.entries[key]
+	if !found {
+		return values, false, false
+	}
+
+	fresh = entry.ttl <= 0 || time.Since(entry.stored) < entry.ttl
+
+	return entry.values, fresh, true
+}
+
+// Set stores completions under key, with a given TTL.
+func (c *Cache) Set(key string, ttl time.Duration, values Values) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[key] = &cacheEntry{
+		values: values,
+		stored: time.Now(),
+		ttl:    ttl,
+	}
+}
+
+// Refresh regenerates the completions cached under key in the background,
+// and stores them when done. Nothing is done if a refresh is already running
+// for this key, or if the key has been invalidated in the meantime.
+func (c *Cache) Refresh(key string, ttl time.Duration, generate func() Values) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, found := c.entries[key]
+	if !found || entry.refreshing {
+		return
+	}
+
+	entry.refreshing = true
+
+	go func() {
+		values := generate()
+
+		stored := time.Now()
+
+		// Errors of background completers are shown distinctly, and
+		// don't replace completions that were working so far: these
+		// are kept stale, so that the next request retries.
+		values.Messages.markAsync()
+
+		if len(values.values) == 0 && values.Messages.HasErrors() {
+			var messages Messages
+			messages.Merge(entry.values.Messages)
+			messages.Merge(values.Messages)
+
+			values = entry.values
+			values.Messages = messages
+			stored, ttl = entry.stored, entry.ttl
+		}
+
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		// The entry might have been invalidated, or replaced.
+		if current, found := c.entries[key]; !found || current != entry {
+			return
+		}
+
+		c.entries[key] = &cacheEntry{
+			values: values,
+			stored: stored,
+			ttl:    ttl,
+		}
+	}()
+}
+
+// Invalidate drops the completions cached under the given keys,
+// or all cached completions if no keys are given.
+func (c *Cache) Invalidate(keys ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(keys) == 0 {
+		c.entries = make(map[string]*cacheEntry)
+		return
+	}
+
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+}
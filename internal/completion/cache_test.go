@@ -0,0 +1,133 @@
+package completion
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	cache := NewCache()
+
+	if _, _, found := cache.Get("key"); found {
+		t.Fatalf("Get() on empty cache found values")
+	}
+
+	cache.Set("key", time.Hour, AddRaw(candidates("a", "b")))
+
+	values, fresh, found := cache.Get("key")
+	if !found || !fresh || len(values.values) != 2 {
+		t.Fatalf("Get() = %v (fresh: %v, found: %v), want 2 fresh values", values.values, fresh, found)
+	}
+
+	// Never expiring values.
+	cache.Set("forever", 0, AddRaw(candidates("a")))
+
+	if _, fresh, _ = cache.Get("forever"); !fresh {
+		t.Errorf("Get() with no TTL is stale, want fresh")
+	}
+
+	cache.Invalidate("key")
+
+	if _, _, found = cache.Get("key"); found {
+		t.Errorf("Get() after Invalidate(key) found values")
+	}
+
+	if _, _, found = cache.Get("forever"); !found {
+		t.Errorf("Get() after Invalidate(key) did not find other key")
+	}
+
+	cache.Invalidate()
+
+	if _, _, found = cache.Get("forever"); found {
+		t.Errorf("Get() after Invalidate() found values")
+	}
+}
+
+func TestCacheRefresh(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", time.Nanosecond, AddRaw(candidates("old")))
+
+	time.Sleep(time.Millisecond)
+
+	values, fresh, found := cache.Get("key")
+	if !found || fresh {
+		t.Fatalf("Get() = fresh: %v, found: %v, want stale values", fresh, found)
+	}
+
+	if values.values[0].Value != "old" {
+		t.Errorf("Get() stale value = %q, want %q", values.values[0].Value, "old")
+	}
+
+	done := make(chan struct{})
+
+	cache.Refresh("key", time.Hour, func() Values {
+		defer close(done)
+		return AddRaw(candidates("new"))
+	})
+
+	<-done
+
+	// Wait for the refreshed values to be stored.
+	for range 100 {
+		if values, fresh, _ = cache.Get("key"); fresh {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if !fresh || values.values[0].Value != "new" {
+		t.Errorf("Get() after Refresh() = %q (fresh: %v), want fresh %q", values.values[0].Value, fresh, "new")
+	}
+}
+
+func TestCacheRefreshError(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", time.Nanosecond, AddRaw(candidates("old")))
+
+	time.Sleep(time.Millisecond)
+
+	cache.Refresh("key", time.Hour, func() Values {
+		values := AddRaw(nil)
+		values.Messages.AddMessage(Message{Text: "connection refused", Level: MessageError})
+
+		return values
+	})
+
+	var values Values
+	var fresh bool
+
+	for range 100 {
+		if values, fresh, _ = cache.Get("key"); values.Messages.HasErrors() {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	// Previous values are kept, but still stale so that they are refreshed again.
+	if fresh || len(values.values) != 1 || values.values[0].Value != "old" {
+		t.Fatalf("Get() after failed Refresh() = %v (fresh: %v), want previous stale values", values.values, fresh)
+	}
+
+	messages := values.Messages.Global()
+	if len(messages) != 1 || !messages[0].Async || messages[0].Level != MessageError {
+		t.Errorf("Get() after failed Refresh() messages = %v, want one async error", messages)
+	}
+
+	cache.Refresh("key", time.Hour, func() Values {
+		return AddRaw(candidates("new"))
+	})
+
+	for range 100 {
+		if values, fresh, _ = cache.Get("key"); fresh {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if !fresh || values.values[0].Value != "new" || !values.Messages.IsEmpty() {
+		t.Errorf("Get() after retried Refresh() = %v (fresh: %v), want fresh values without errors", values.values, fresh)
+	}
+}
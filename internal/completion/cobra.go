@@ -0,0 +1,144 @@
+package completion
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CobraDirective is the bitmask of directives printed by cobra programs
+// at the end of the output of their hidden __complete command.
+type CobraDirective int
+
+// Directives of the cobra completion protocol.
+const (
+	CobraError         CobraDirective = 1 << iota // An error occurred, completions should be ignored.
+	CobraNoSpace                                  // Don't add a space after the completion.
+	CobraNoFileComp                               // Don't fall back to file completion when there are no candidates.
+	CobraFilterFileExt                            // Candidates are file extensions, to filter file completions with.
+	CobraFilterDirs                               // Only complete directories, within the candidate directory if any.
+	CobraKeepOrder                                // Don't sort the candidates.
+
+	// CobraDefault is the directive of programs not returning any.
+	CobraDefault CobraDirective = 0
+)
+
+// cobraActiveHelp prefixes the candidates that are actually help messages.
+const cobraActiveHelp = "_activeHelp_ "
+
+var (
+	errCobraOutputTooLarge = errors.New("completion output too large")
+	errCobraNoDirective    = errors.New("missing completion directive")
+)
+
+// RunCobra runs the __complete command of a cobra program with the arguments
+// preceding the word being completed, plus the latter. The command is killed
+// if it does not complete within the timeout, and if it outputs more than maxOutput
+// bytes, the output is discarded and an error is returned.
+func RunCobra(binary string, args []string, timeout time.Duration, maxOutput int) (CobraCompletions, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: maxOutput, full: cancel}
+
+	cmd := exec.CommandContext(ctx, binary, append([]string{"__complete"}, args...)...)
+	cmd.Stdout = stdout
+	cmd.WaitDelay = timeout
+
+	err := cmd.Run()
+
+	switch {
+	case stdout.truncated:
+		return CobraCompletions{}, errCobraOutputTooLarge
+	case ctx.Err() != nil:
+		return CobraCompletions{}, fmt.Errorf("completion timed out after %s", timeout)
+	case err != nil:
+		return CobraCompletions{}, err
+	}
+
+	return ParseCobra(stdout.buf.Bytes())
+}
+
+// CobraCompletions holds the candidates, help messages
+// and directive returned by a cobra __complete command.
+type CobraCompletions struct {
+	Values    RawValues
+	Messages  []string
+	Directive CobraDirective
+}
+
+// ParseCobra parses the output of a cobra __complete command: one candidate per line,
+// optionally followed by a tab and its description, and the directive as a last line.
+func ParseCobra(output []byte) (CobraCompletions, error) {
+	var comps CobraCompletions
+
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+
+	last := lines[len(lines)-1]
+	if !strings.HasPrefix(last, ":") {
+		return comps, errCobraNoDirective
+	}
+
+	directive, err := strconv.Atoi(last[1:])
+	if err != nil {
+		return comps, fmt.Errorf("invalid completion directive: %w", err)
+	}
+
+	comps.Directive = CobraDirective(directive)
+
+	for _, line := range lines[:len(lines)-1] {
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, cobraActiveHelp) {
+			comps.Messages = append(comps.Messages, strings.TrimPrefix(line, cobraActiveHelp))
+			continue
+		}
+
+		value, description, _ := strings.Cut(line, "\t")
+
+		comps.Values = append(comps.Values, Candidate{
+			Value:       value,
+			Display:     value,
+			Description: description,
+		})
+	}
+
+	return comps, nil
+}
+
+// Has returns true if the directive includes all the given ones.
+func (d CobraDirective) Has(directive CobraDirective) bool {
+	return d&directive == directive
+}
+
+// limitedBuffer is a buffer discarding all output once it has reached its size limit,
+// in which case it calls its full function (to kill the process writing to it). Writes
+// never fail, so that the process can never block on a full output pipe.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	full      func()
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.truncated {
+		return len(p), nil
+	}
+
+	if b.buf.Len()+len(p) > b.limit {
+		b.truncated = true
+		b.full()
+
+		return len(p), nil
+	}
+
+	return b.buf.Write(p)
+}
@@ -0,0 +1,217 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseCobra(t *testing.T) {
+	tests := []struct {
+		name          string
+		output        string
+		wantValues    []string
+		wantDescs     []string
+		wantMessages  []string
+		wantDirective CobraDirective
+		wantErr       bool
+	}{
+		{
+			name:          "Candidates with descriptions",
+			output:        "get\tDisplay resources\napply\tApply a configuration\n:4\n",
+			wantValues:    []string{"get", "apply"},
+			wantDescs:     []string{"Display resources", "Apply a configuration"},
+			wantDirective: CobraNoFileComp,
+		},
+		{
+			name:          "Active help and combined directives",
+			output:        "_activeHelp_ Expects a pod name\npod-a\n:6\n",
+			wantValues:    []string{"pod-a"},
+			wantDescs:     []string{""},
+			wantMessages:  []string{"Expects a pod name"},
+			wantDirective: CobraNoSpace | CobraNoFileComp,
+		},
+		{
+			name:          "No candidates",
+			output:        ":0\n",
+			wantDirective: CobraDefault,
+		},
+		{
+			name:    "Missing directive",
+			output:  "get\napply\n",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid directive",
+			output:  "get\n:abc\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCobra([]byte(tt.output))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCobra() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(got.Values) != len(tt.wantValues) {
+				t.Fatalf("ParseCobra() values = %v, want %v", got.Values, tt.wantValues)
+			}
+
+			for i, comp := range got.Values {
+				if comp.Value != tt.wantValues[i] || comp.Description != tt.wantDescs[i] {
+					t.Errorf("ParseCobra() value %d = %q (%q), want %q (%q)",
+						i, comp.Value, comp.Description, tt.wantValues[i], tt.wantDescs[i])
+				}
+			}
+
+			if len(got.Messages) != len(tt.wantMessages) {
+				t.Errorf("ParseCobra() messages = %v, want %v", got.Messages, tt.wantMessages)
+			}
+
+			if got.Directive != tt.wantDirective {
+				t.Errorf("ParseCobra() directive = %d, want %d", got.Directive, tt.wantDirective)
+			}
+		})
+	}
+}
+
+func TestRunCobra(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		timeout    time.Duration
+		max        int
+		wantValues []string
+		wantErr    bool
+	}{
+		{
+			name:       "Arguments passed to __complete",
+			script:     `[ "$1" = __complete ] && shift; for arg in "$@"; do echo "arg-$arg"; done; echo ":4"`,
+			timeout:    time.Second,
+			max:        1024,
+			wantValues: []string{"arg-get", "arg-po"},
+		},
+		{
+			name:    "Timeout",
+			script:  `sleep 5; echo ":0"`,
+			timeout: 100 * time.Millisecond,
+			max:     1024,
+			wantErr: true,
+		},
+		{
+			name:    "Output too large",
+			script:  `i=0; while [ $i -lt 100 ]; do echo "candidate-$i"; i=$((i+1)); done; echo ":0"`,
+			timeout: time.Second,
+			max:     64,
+			wantErr: true,
+		},
+		{
+			name:    "Failing program",
+			script:  `exit 1`,
+			timeout: time.Second,
+			max:     1024,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := filepath.Join(t.TempDir(), "stub")
+			if err := os.WriteFile(stub, []byte("#!/bin/sh\n"+tt.script+"\n"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now()
+
+			got, err := RunCobra(stub, []string{"get", "po"}, tt.timeout, tt.max)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunCobra() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if elapsed := time.Since(start); elapsed > 3*time.Second {
+				t.Errorf("RunCobra() took %s, exceeding its timeout", elapsed)
+			}
+
+			if len(got.Values) != len(tt.wantValues) {
+				t.Fatalf("RunCobra() values = %v, want %v", got.Values, tt.wantValues)
+			}
+
+			for i, comp := range got.Values {
+				if comp.Value != tt.wantValues[i] {
+					t.Errorf("RunCobra() value %d = %q, want %q", i, comp.Value, tt.wantValues[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	root := t.TempDir()
+
+	for _, dir := range []string{"src", "docs", ".git"} {
+		if err := os.Mkdir(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, file := range []string{"main.go", "go.mod", "config.yaml", "src/lib.go", ".env"} {
+		if err := os.WriteFile(filepath.Join(root, file), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		prefix     string
+		dirsOnly   bool
+		extensions []string
+		want       []string
+	}{
+		{
+			name:   "All visible files",
+			prefix: "",
+			want:   []string{"config.yaml", "docs/", "go.mod", "main.go", "src/"},
+		},
+		{
+			name:   "Hidden files",
+			prefix: ".",
+			want:   []string{".env", ".git/"},
+		},
+		{
+			name:       "Filtered extensions",
+			prefix:     "",
+			extensions: []string{"go", ".yaml"},
+			want:       []string{"config.yaml", "docs/", "main.go", "src/"},
+		},
+		{
+			name:     "Directories only",
+			prefix:   "",
+			dirsOnly: true,
+			want:     []string{"docs/", "src/"},
+		},
+		{
+			name:   "Subdirectory prefix",
+			prefix: "src/l",
+			want:   []string{"src/lib.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Files(root, tt.prefix, tt.dirsOnly, tt.extensions...)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Files() = %v, want %v", got, tt.want)
+			}
+
+			for i, comp := range got {
+				if comp.Value != tt.want[i] {
+					t.Errorf("Files() value %d = %q, want %q", i, comp.Value, tt.want[i])
+				}
+			}
+		})
+	}
+}
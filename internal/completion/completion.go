@@ -13,26 +13,44 @@ type Candidate struct {
 	Style       string // An arbitrary string of color/text effects to use when displaying the completion.
 	Tag         string // All completions with the same tag are grouped together and displayed under the tag heading.
 
+	// Accept, if not nil, is called when the candidate is accepted, that is, when it
+	// becomes part of the real input line. It can rewrite the line, move the cursor
+	// and ask for completions to be generated again immediately.
+	Accept AcceptFunc
+
 	// A list of runes that are automatically trimmed when a space or a non-nil character is
 	// inserted immediately after the completion. This is used for slash-autoremoval in path
 	// completions, comma-separated completions, etc.
 	noSpace SuffixMatcher
 
+	// quoted is true when the value has already been quoted/escaped
+	// for insertion in the current word, by Values.Quote.
+	quoted bool
+
 	displayLen int // Real length of the displayed candidate, that is not counting escaped sequences.
 	descLen    int
 }
 
+// AcceptFunc is passed the input line and cursor position once a candidate has been
+// inserted, and returns the new line and cursor position. If complete is true, the
+// shell immediately starts a new completion at the new cursor position.
+type AcceptFunc func(line []rune, cursor int) (newLine []rune, newCursor int, complete bool)
+
 // Values is used internally to hold all completion candidates and their associated data.
 type Values struct {
-	values   RawValues
-	Messages Messages
-	NoSpace  SuffixMatcher
-	Usage    string
-	ListLong map[string]bool
-	NoSort   map[string]bool
-	ListSep  map[string]string
-	Pad      map[string]bool
-	Escapes  map[string]bool
+	values     RawValues
+	Messages   Messages
+	NoSpace    SuffixMatcher
+	Usage      string
+	ListLong   map[string]bool
+	Horizontal map[string]bool
+	NoSort     map[string]bool
+	ListSep    map[string]string
+	Pad        map[string]bool
+	Escapes    map[string]bool
+	ExpandDesc map[string]bool
+	Tree       map[string]string
+	Preview    Previewer
 
 	// Initially this will be set to the part of the current word
 	// from the beginning of the word up to the position of the cursor.
@@ -49,10 +67,13 @@ type Values struct {
 // AddRaw adds completion values in bulk.
 func AddRaw(values []Candidate) Values {
 	return Values{
-		values:   RawValues(values),
-		ListLong: make(map[string]bool),
-		NoSort:   make(map[string]bool),
-		ListSep:  make(map[string]string),
-		Pad:      make(map[string]bool),
+		values:     RawValues(values),
+		ListLong:   make(map[string]bool),
+		Horizontal: make(map[string]bool),
+		NoSort:     make(map[string]bool),
+		ListSep:    make(map[string]string),
+		Pad:        make(map[string]bool),
+		ExpandDesc: make(map[string]bool),
+		Tree:       make(map[string]string),
 	}
 }
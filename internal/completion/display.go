@@ -7,12 +7,19 @@ import (
 	"strings"
 
 	"github.com/reeflective/readline/internal/color"
+	"github.com/reeflective/readline/internal/strutil"
 	"github.com/reeflective/readline/internal/term"
 )
 
 // Display prints the current completion list to the screen,
 // respecting the current display and completion settings.
 func Display(eng *Engine, maxRows int) {
+	fmt.Print(Render(eng, maxRows))
+}
+
+// Render returns the current completion list as printed by Display(),
+// its rows separated by newlines, without a newline after the last one.
+func Render(eng *Engine, maxRows int) string {
 	eng.usedY = 0
 
 	// The completion engine might be inactive but still having
@@ -21,8 +28,7 @@ func Display(eng *Engine, maxRows int) {
 	// little more time. The engine itself is responsible for
 	// deleting those lists when it deems them useless.
 	if eng.Matches() == 0 || eng.skipDisplay {
-		fmt.Print(term.ClearLineAfter)
-		return
+		return term.ClearLineAfter
 	}
 
 	// The final completions string to print.
@@ -32,12 +38,25 @@ func Display(eng *Engine, maxRows int) {
 		completions += eng.renderCompletions(group)
 	}
 
+	// The preview pane of the selected candidate, if any, is either
+	// on the right of the completions, or uses some of the rows below.
+	if eng.previewWidth() > 0 {
+		completions, _ = eng.cropCompletions(completions, maxRows)
+		completions, eng.usedY = eng.renderPreviewBeside(completions, maxRows)
+
+		return completions
+	}
+
+	preview, previewRows := eng.renderPreview(maxRows)
+
 	// Crop the completions so that it fits within our terminal
-	completions, eng.usedY = eng.cropCompletions(completions, maxRows)
+	completions, eng.usedY = eng.cropCompletions(completions, maxRows-previewRows)
 
-	if completions != "" {
-		fmt.Print(completions)
+	if preview != "" {
+		eng.usedY += previewRows
 	}
+
+	return completions + preview
 }
 
 // Coordinates returns the number of terminal rows used
@@ -60,7 +79,14 @@ func (e *Engine) renderCompletions(grp *group) string {
 		builder.WriteString(tag + term.ClearLineAfter + term.NewlineReturn)
 	}
 
+	for _, msg := range grp.messages {
+		builder.WriteString(e.renderMessage(msg) + term.ClearLineAfter + term.NewlineReturn)
+	}
+
 	for rowIndex, row := range grp.rows {
+		var wrapped []string
+		var indent, width int
+
 		for columnIndex := range grp.columnsWidth {
 			var value Candidate
 
@@ -76,6 +102,13 @@ func (e *Engine) renderCompletions(grp *group) string {
 			isSelected := rowIndex == grp.posY && columnIndex == grp.posX && grp.isCurrent
 			display := e.highlightDisplay(grp, value, padding, columnIndex, isSelected)
 
+			// Remember where columns start, for mouse selection.
+			if rowIndex == 0 {
+				grp.columnsX = append(grp.columnsX[:columnIndex], width)
+			}
+
+			width += strutil.RealLength(display)
+
 			builder.WriteString(display)
 
 			// Add description if no aliases, or if done with them.
@@ -87,14 +120,30 @@ func (e *Engine) renderCompletions(grp *group) string {
 			if !grp.aliased || onLast {
 				grp.maxDescAllowed = grp.setMaximumSizes(columnIndex)
 
+				// Wrapped descriptions are printed on the candidate
+				// row, and on the next ones, aligned with the first.
+				lines := grp.descriptionLines(value, isSelected)
+				if len(lines) > 1 {
+					value.Description = lines[0]
+					value.descLen = strutil.RealLength(lines[0])
+					wrapped = lines[1:]
+					indent = strutil.RealLength(display) + len(grp.listSep())
+				}
+
 				descPad := grp.getPad(value, columnIndex, true)
 				desc := e.highlightDesc(grp, value, descPad, rowIndex, columnIndex, isSelected)
+				width += strutil.RealLength(desc)
+
 				builder.WriteString(desc)
 			}
 		}
 
 		// We're done for this line.
 		builder.WriteString(term.ClearLineAfter + term.NewlineReturn)
+
+		for _, line := range wrapped {
+			builder.WriteString(padSpace(indent) + e.highlightDescLine(grp, line, rowIndex) + term.ClearLineAfter + term.NewlineReturn)
+		}
 	}
 
 	return builder.String()
@@ -136,6 +185,12 @@ func (e *Engine) highlightDisplay(grp *group, val Candidate, pad, col int, selec
 		candidate = style + candidate + color.Reset
 	}
 
+	// Marked candidates are always highlighted, even when selected.
+	if e.isMarked(grp, val) {
+		markStyle := color.UnquoteRC(e.config.GetString("completion-marked-style"))
+		candidate = markStyle + candidate
+	}
+
 	return candidate + padded
 }
 
@@ -169,6 +224,18 @@ func (e *Engine) highlightDesc(grp *group, val Candidate, pad, row, col int, sel
 	return compDescStyle + desc + color.Reset + padded
 }
 
+// highlightDescLine styles the continuation line of a wrapped description.
+func (e *Engine) highlightDescLine(grp *group, line string, row int) string {
+	style := color.UnquoteRC(e.config.GetString("completion-description-style"))
+
+	if row == grp.posY && grp.isCurrent {
+		userDescStyle := color.UnquoteRC(e.config.GetString("completion-selection-style"))
+		style += color.Fmt(color.Bg+"255") + userDescStyle
+	}
+
+	return style + line + color.Reset
+}
+
 // cropCompletions - When the user cycles through a completion list longer
 // than the console MaxTabCompleterRows value, we crop the completions string
 // so that "global" cycling (across all groups) is printed correctly.
@@ -181,6 +248,7 @@ func (e *Engine) cropCompletions(comps string, maxRows int) (cropped string, use
 
 	// If absPos < MaxTabCompleterRows, cut below MaxTabCompleterRows and return
 	if absPos < maxRows-1 {
+		e.scrollY = 0
 		return e.cutCompletionsBelow(scanner, maxRows)
 	}
 
@@ -224,6 +292,7 @@ func (e *Engine) cutCompletionsBelow(scanner *bufio.Scanner, maxRows int) (strin
 
 func (e *Engine) cutCompletionsAboveBelow(scanner *bufio.Scanner, maxRows, absPos int) (string, int) {
 	cutAbove := absPos - maxRows + 1
+	e.scrollY = cutAbove + 1
 
 	var cropped string
 	var count int
@@ -14,8 +14,10 @@ import (
 type Engine struct {
 	config        *inputrc.Config // The inputrc contains options relative to completion.
 	cached        Completer       // A cached completer function to use when updating.
+	results       *Cache          // Completion results cached under user-defined keys.
 	autoCompleter Completer       // Completer used by things like autocomplete
 	hint          *ui.Hint        // The completions can feed hint/usage messages
+	bell          *ui.Bell        // Rings when incremental searches fail.
 
 	// Line parameters
 	keys       *core.Keys      // The input keys reader
@@ -33,10 +35,27 @@ type Engine struct {
 	prefix      string        // The current tab completion prefix against which to build candidates
 	suffix      string        // The current word suffix
 	inserted    []rune        // The selected candidate (inserted in line) without prefix or suffix.
+	marked      []Candidate   // Candidates marked for insertion, in the order they were marked.
 	usedY       int           // Comprehensive size offset (terminal rows) of the currently built completions.
+	scrollY     int           // Number of completion rows cropped above the displayed ones.
 	auto        bool          // Is the engine autocompleting ?
 	autoForce   bool          // Special autocompletion mode (isearch-style)
 	skipDisplay bool          // Don't display completions if there are some.
+	ambiguous   string        // The line (and cursor) for which the last common-prefix completion was ambiguous.
+	followUp    bool          // The last accepted candidate asked for a new completion.
+	frecency    *Frecency     // Rankings of accepted candidates, if enabled.
+	ranking     *Frecency     // Rankings used to order the current completions.
+	frecencyErr error         // Error loading/saving rankings, not reported yet.
+
+	// Preview pane
+	previewer      Previewer           // Produces the preview of the selected candidate, if any.
+	previews       map[string]*preview // Previews produced (or being produced) for the current completions.
+	previewRefresh func()              // Refreshes the interface when a preview is done in the background.
+	previewKey     string              // Identifies the candidate for which preview lines are computed.
+	previewDone    bool                // The preview lines are not a placeholder for a running previewer.
+	previewLines   []string            // Preview lines of the selected candidate.
+	previewOffset  int                 // The first preview line displayed.
+	previewHeight  int                 // Number of preview lines displayed.
 
 	// Incremental search
 	IsearchRegex       *regexp.Regexp // Holds the current search regex match
@@ -50,15 +69,19 @@ type Engine struct {
 	isearchStartBuf    string         // The buffer before starting isearch
 	isearchStartCursor int            // The cursor position before starting isearch
 	isearchLast        string         // The last non-incremental buffer.
+	isearchPrevBuf     string         // The minibuffer when the matches were last updated.
+	isearchFound       bool           // Whether the previous minibuffer had matches.
 	isearchModeExit    keymap.Mode    // The main keymap to restore after exiting isearch
 }
 
 // NewEngine initializes a new completion engine with the shell operating parameters.
-func NewEngine(h *ui.Hint, km *keymap.Engine, o *inputrc.Config) *Engine {
+func NewEngine(h *ui.Hint, b *ui.Bell, km *keymap.Engine, o *inputrc.Config) *Engine {
 	return &Engine{
-		config: o,
-		hint:   h,
-		keymap: km,
+		config:  o,
+		hint:    h,
+		bell:    b,
+		keymap:  km,
+		results: NewCache(),
 	}
 }
 
@@ -75,12 +98,13 @@ func Init(eng *Engine, k *core.Keys, l *core.Line, cur *core.Cursor, s *core.Sel
 
 // Generate uses a list of completions to group/order and prepares completions before printing them.
 // If either no completions or only one is available after all constraints are applied, the engine
-// will automatically insert/accept and/or reset itself.
-func (e *Engine) Generate(completions Values) {
+// will automatically insert/accept and/or reset itself. It returns false if there are no completions.
+func (e *Engine) Generate(completions Values) (found bool) {
 	e.prepare(completions)
 
 	if e.noCompletions() {
 		e.ClearMenu(true)
+		return false
 	}
 
 	// Incremental search is a special case, because the user may
@@ -90,19 +114,22 @@ func (e *Engine) Generate(completions Values) {
 		e.acceptCandidate()
 		e.ClearMenu(true)
 	}
+
+	return true
 }
 
 // GenerateWith generates completions with a completer function, itself cached
 // so that the next time it must update its results, it can reuse this completer.
-func (e *Engine) GenerateWith(completer Completer) {
+// It returns false if there is no completer, or if it produced no completions.
+func (e *Engine) GenerateWith(completer Completer) (found bool) {
 	e.cached = completer
 	if e.cached == nil {
-		return
+		return false
 	}
 
 	// Call the provided/cached completer
 	// and use the completions as normal
-	e.Generate(e.cached())
+	return e.Generate(e.cached())
 }
 
 // GenerateCached simply recomputes the grid of completions with the pool
@@ -112,6 +139,11 @@ func (e *Engine) GenerateCached() {
 	e.GenerateWith(e.cached)
 }
 
+// Results returns the cache storing completion results under user-defined keys.
+func (e *Engine) Results() *Cache {
+	return e.results
+}
+
 // SkipDisplay avoids printing completions below the
 // input line, but still enables cycling through them.
 func (e *Engine) SkipDisplay() {
@@ -130,16 +162,22 @@ func (e *Engine) Select(row, column int) {
 	// Ensure the completion keymaps are set.
 	e.adjustSelectKeymap()
 
-	// Some keys used to move around completions
-	// will influence the coordinates' offsets.
-	row, column = e.adjustCycleKeys(row, column)
-
 	// If we already have an inserted candidate
 	// remove it before inserting the new one.
 	if len(e.selected.Value) > 0 {
 		e.cancelCompletedLine()
 	}
 
+	// In tree groups, left and right collapse and expand nodes.
+	if grp.toggleTree(string(e.keys.Caller())) {
+		e.insertCandidate()
+		return
+	}
+
+	// Some keys used to move around completions
+	// will influence the coordinates' offsets.
+	row, column = e.adjustCycleKeys(row, column)
+
 	defer e.refreshLine()
 
 	// Move the selector
@@ -216,11 +254,30 @@ func (e *Engine) Cancel(inserted, cached bool) {
 		e.compLine.Set(*e.line...)
 		e.compCursor.Set(e.cursor.Pos())
 	} else {
+		accepted := e.acceptedCandidates()
+
+		for _, comp := range accepted {
+			e.recordAccepted(comp)
+		}
+
 		e.line.Set(*e.compLine...)
 		e.cursor.Set(e.compCursor.Pos())
+
+		for _, comp := range accepted {
+			e.runAcceptAction(comp)
+		}
 	}
 }
 
+// FollowUp returns true if the last accepted candidate asked for
+// completions to be generated again, and resets this request.
+func (e *Engine) FollowUp() bool {
+	followUp := e.followUp
+	e.followUp = false
+
+	return followUp
+}
+
 // ResetForce drops any currently inserted candidate from the line,
 // drops any cached completer function and generated list, and exits
 // the incremental-search mode.
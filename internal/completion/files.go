@@ -0,0 +1,76 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Files returns the files and directories matching a path prefix, relative to a root
+// directory (the working directory if empty). If dirsOnly is true, only directories
+// are returned, and if extensions are given, only files with one of them (directories
+// always are). Directory values have a trailing slash and are tagged "directories",
+// while other files are tagged "files".
+func Files(root, prefix string, dirsOnly bool, extensions ...string) RawValues {
+	dir, base := "", prefix
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		dir, base = prefix[:i+1], prefix[i+1:]
+	}
+
+	path := dir
+	if root != "" && !filepath.IsAbs(dir) {
+		path = filepath.Join(root, dir)
+	}
+
+	if path == "" {
+		path = "."
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil
+	}
+
+	var values RawValues
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		// Hidden files are only completed when asked for.
+		if !strings.HasPrefix(name, base) || strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+
+		isDir := entry.IsDir()
+		if !isDir && entry.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(path, name)); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+
+		switch {
+		case isDir:
+			values = append(values, Candidate{Value: dir + name + "/", Display: name + "/", Tag: "directories"})
+		case dirsOnly, !hasExtension(name, extensions):
+			continue
+		default:
+			values = append(values, Candidate{Value: dir + name, Display: name, Tag: "files"})
+		}
+	}
+
+	return values
+}
+
+func hasExtension(name string, extensions []string) bool {
+	if len(extensions) == 0 {
+		return true
+	}
+
+	for _, ext := range extensions {
+		if strings.HasSuffix(name, "."+strings.TrimPrefix(ext, ".")) {
+			return true
+		}
+	}
+
+	return false
+}
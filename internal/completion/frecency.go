@@ -0,0 +1,319 @@
+package completion
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reeflective/readline/internal/color"
+)
+
+// maxFrecencyCount is the total number of accepted candidates in a completion context
+// past which all counts are aged, so that old favorites eventually make way for new ones.
+const maxFrecencyCount = 1000
+
+// Frecency ranks completion candidates by how frequently and how recently they have
+// been accepted, per completion context (a command and a completion tag), and stores
+// these rankings in a small tab-separated file.
+type Frecency struct {
+	path     string
+	contexts map[string]map[string]*frecencyEntry
+	now      func() time.Time
+	modified bool
+}
+
+type frecencyEntry struct {
+	count float64
+	last  int64
+}
+
+// LoadFrecency loads the rankings stored in a file, which is created on the first
+// candidate recorded if it does not exist. A file that cannot be parsed is reset.
+func LoadFrecency(path string) (*Frecency, error) {
+	f := &Frecency{
+		path:     path,
+		contexts: make(map[string]map[string]*frecencyEntry),
+		now:      time.Now,
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	} else if err != nil {
+		return f, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 5 {
+			continue
+		}
+
+		count, errCount := strconv.ParseFloat(fields[3], 64)
+		last, errLast := strconv.ParseInt(fields[4], 10, 64)
+
+		if errCount != nil || errLast != nil {
+			continue
+		}
+
+		f.entries(fields[0], fields[1])[fields[2]] = &frecencyEntry{count: count, last: last}
+	}
+
+	return f, scanner.Err()
+}
+
+// Record increases the rank of a candidate accepted for a command and a tag.
+// The rankings are only written to the file when saved.
+func (f *Frecency) Record(command, tag, value string) {
+	if value == "" || strings.ContainsAny(command+tag+value, "\t\n") {
+		return
+	}
+
+	entries := f.entries(command, tag)
+
+	entry, found := entries[value]
+	if !found {
+		entry = &frecencyEntry{}
+		entries[value] = entry
+	}
+
+	entry.count++
+	entry.last = f.now().Unix()
+
+	f.age(entries)
+
+	f.modified = true
+}
+
+// Save writes the rankings to the file, if some candidates have been recorded since
+// they were loaded or last saved. The file is replaced at once, never half-written.
+func (f *Frecency) Save() error {
+	if !f.modified {
+		return nil
+	}
+
+	if err := f.save(); err != nil {
+		return err
+	}
+
+	f.modified = false
+
+	return nil
+}
+
+// Score returns the rank of a candidate for a command and a tag:
+// the number of times it was accepted, weighted by how recently.
+func (f *Frecency) Score(command, tag, value string) float64 {
+	entry, found := f.contexts[frecencyContext(command, tag)][value]
+	if !found {
+		return 0
+	}
+
+	switch age := f.now().Sub(time.Unix(entry.last, 0)); {
+	case age < time.Hour:
+		return entry.count * 4
+	case age < 24*time.Hour:
+		return entry.count * 2
+	case age < 7*24*time.Hour:
+		return entry.count / 2
+	default:
+		return entry.count / 4
+	}
+}
+
+// Sort orders candidates by decreasing rank, keeping the current
+// order of candidates with the same rank (usually alphabetical).
+func (f *Frecency) Sort(command string, values RawValues) {
+	scores := make(map[string]float64, len(values))
+
+	for _, val := range values {
+		scores[val.Tag+"\t"+val.Value] = f.Score(command, val.Tag, color.Strip(val.Value))
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		return scores[values[i].Tag+"\t"+values[i].Value] > scores[values[j].Tag+"\t"+values[j].Value]
+	})
+}
+
+// clone returns a copy of the rankings, which
+// is not affected by candidates recorded later.
+func (f *Frecency) clone() *Frecency {
+	clone := &Frecency{
+		path:     f.path,
+		contexts: make(map[string]map[string]*frecencyEntry, len(f.contexts)),
+		now:      f.now,
+	}
+
+	for context, entries := range f.contexts {
+		cloned := make(map[string]*frecencyEntry, len(entries))
+		for value, entry := range entries {
+			cloned[value] = &frecencyEntry{count: entry.count, last: entry.last}
+		}
+
+		clone.contexts[context] = cloned
+	}
+
+	return clone
+}
+
+func (f *Frecency) entries(command, tag string) map[string]*frecencyEntry {
+	context := frecencyContext(command, tag)
+
+	entries, found := f.contexts[context]
+	if !found {
+		entries = make(map[string]*frecencyEntry)
+		f.contexts[context] = entries
+	}
+
+	return entries
+}
+
+// age decreases all counts of a context when their total gets too high,
+// and forgets about candidates which have not been accepted in a long time.
+func (f *Frecency) age(entries map[string]*frecencyEntry) {
+	var total float64
+	for _, entry := range entries {
+		total += entry.count
+	}
+
+	if total <= maxFrecencyCount {
+		return
+	}
+
+	for value, entry := range entries {
+		entry.count *= 0.9
+		if entry.count < 1 {
+			delete(entries, value)
+		}
+	}
+}
+
+func (f *Frecency) save() error {
+	var lines []string
+
+	for context, entries := range f.contexts {
+		for value, entry := range entries {
+			lines = append(lines, fmt.Sprintf("%s\t%s\t%g\t%d", context, value, entry.count, entry.last))
+		}
+	}
+
+	sort.Strings(lines)
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that
+	// the rankings are never left half-written.
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, f.path)
+}
+
+func frecencyContext(command, tag string) string {
+	return command + "\t" + tag
+}
+
+//
+// Engine ---------------------------------------------------------------------------
+//
+
+// loadFrecency returns the rankings stored in the completion-frecency-file,
+// loading them if needed, or nil if ranking candidates is not enabled.
+func (e *Engine) loadFrecency() *Frecency {
+	path := e.config.GetString("completion-frecency-file")
+	if path == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+
+	if e.frecency == nil || e.frecency.path != path {
+		var err error
+
+		e.SaveFrecency()
+
+		if e.frecency, err = LoadFrecency(path); err != nil {
+			e.frecencyErr = fmt.Errorf("failed to load completion rankings: %w", err)
+		}
+	}
+
+	return e.frecency
+}
+
+// SaveFrecency writes the rankings of the candidates accepted so far to the
+// completion-frecency-file. The shell calls it once per input line, instead
+// of on each accepted candidate. Errors are reported with next completions.
+func (e *Engine) SaveFrecency() {
+	if e.frecency == nil {
+		return
+	}
+
+	if err := e.frecency.Save(); err != nil {
+		e.frecencyErr = fmt.Errorf("failed to save completion rankings: %w", err)
+	}
+}
+
+// rankCandidates takes a snapshot of the rankings when new completions
+// are generated, so that the menu order does not change until it is closed.
+// Any error met when loading or saving the rankings is added as a message.
+func (e *Engine) rankCandidates(completions *Values) {
+	defer func() {
+		if e.frecencyErr != nil {
+			completions.Messages.AddMessage(Message{Text: e.frecencyErr.Error(), Level: MessageWarning})
+			e.frecencyErr = nil
+		}
+	}()
+
+	if e.ranking != nil {
+		return
+	}
+
+	if frecency := e.loadFrecency(); frecency != nil {
+		e.ranking = frecency.clone()
+	}
+}
+
+// recordAccepted increases the rank of a candidate being accepted.
+// This must be called before the candidate is inserted in the line.
+func (e *Engine) recordAccepted(comp Candidate) {
+	frecency := e.loadFrecency()
+	if frecency == nil {
+		return
+	}
+
+	frecency.Record(e.command(), comp.Tag, color.Strip(comp.Value))
+}
+
+// command returns the first word of the line, or nothing if the
+// word being completed is the first one (commands themselves).
+func (e *Engine) command() string {
+	pos := e.cursor.Pos() - len(e.prefix)
+	if pos < 0 || pos > e.line.Len() {
+		return ""
+	}
+
+	words := strings.Fields(string((*e.line)[:pos]))
+	if len(words) == 0 {
+		return ""
+	}
+
+	return words[0]
+}
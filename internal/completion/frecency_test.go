@@ -0,0 +1,154 @@
+package completion
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/reeflective/readline/inputrc"
+	"github.com/reeflective/readline/internal/core"
+)
+
+func TestFrecencySort(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		records []string
+		ages    []time.Duration
+		command string
+		want    []string
+	}{
+		{
+			name:    "No records keeps order",
+			command: "git",
+			want:    []string{"add", "commit", "push"},
+		},
+		{
+			name:    "Most frequent first",
+			records: []string{"push", "push", "commit"},
+			ages:    []time.Duration{0, 0, 0},
+			command: "git",
+			want:    []string{"push", "commit", "add"},
+		},
+		{
+			name:    "Recent beats frequent but old",
+			records: []string{"push", "push", "push", "commit"},
+			ages:    []time.Duration{30 * 24 * time.Hour, 30 * 24 * time.Hour, 30 * 24 * time.Hour, 0},
+			command: "git",
+			want:    []string{"commit", "push", "add"},
+		},
+		{
+			name:    "Other command context ignored",
+			records: []string{"push"},
+			ages:    []time.Duration{0},
+			command: "hg",
+			want:    []string{"add", "commit", "push"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "frecency")
+
+			frecency, err := LoadFrecency(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, value := range tt.records {
+				frecency.now = func() time.Time { return now.Add(-tt.ages[i]) }
+				frecency.Record("git", "commands", value)
+			}
+
+			if err := frecency.Save(); err != nil {
+				t.Fatal(err)
+			}
+
+			// Rankings must survive being persisted.
+			loaded, err := LoadFrecency(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			loaded.now = func() time.Time { return now }
+
+			values := candidates("add", "commit", "push")
+			for i := range values {
+				values[i].Tag = "commands"
+			}
+
+			loaded.Sort(tt.command, values)
+
+			for i, want := range tt.want {
+				if values[i].Value != want {
+					t.Errorf("Sort() = %v, want %v", gridValues([][]Candidate{values}), tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestFrecencyClone(t *testing.T) {
+	frecency, err := LoadFrecency(filepath.Join(t.TempDir(), "frecency"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clone := frecency.clone()
+
+	frecency.Record("git", "commands", "push")
+
+	if score := clone.Score("git", "commands", "push"); score != 0 {
+		t.Errorf("clone Score() = %v after recording in original, want 0", score)
+	}
+}
+
+func TestEngineSaveFrecency(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rankings", "frecency")
+
+	config := inputrc.NewDefaultConfig()
+	config.Set("completion-frecency-file", path)
+
+	line := core.Line([]rune("git "))
+	eng := &Engine{config: config, line: &line, cursor: core.NewCursor(&line)}
+	eng.cursor.Set(line.Len())
+
+	eng.recordAccepted(Candidate{Value: "push", Tag: "commands"})
+
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("rankings written before being saved")
+	}
+
+	eng.SaveFrecency()
+
+	if loaded, err := LoadFrecency(path); err != nil || loaded.Score("git", "commands", "push") == 0 {
+		t.Errorf("rankings not saved: %v", err)
+	}
+
+	// Errors are reported with the next completions.
+	os.RemoveAll(filepath.Dir(path))
+	os.WriteFile(filepath.Dir(path), nil, 0o600)
+
+	eng.recordAccepted(Candidate{Value: "pull", Tag: "commands"})
+	eng.SaveFrecency()
+
+	var values Values
+	eng.rankCandidates(&values)
+
+	if messages := values.Messages.All(); len(messages) != 1 || messages[0].Level != MessageWarning {
+		t.Errorf("rankCandidates() messages = %v, want a save error", messages)
+	}
+
+	values = Values{}
+	eng.rankCandidates(&values)
+
+	if messages := values.Messages.All(); len(messages) != 0 {
+		t.Errorf("rankCandidates() messages = %v, want errors reported once", messages)
+	}
+}
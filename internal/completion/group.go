@@ -8,7 +8,7 @@ import (
 	"strings"
 
 	"github.com/reeflective/readline/internal/color"
-	"github.com/reeflective/readline/internal/term"
+	"github.com/reeflective/readline/internal/strutil"
 )
 
 // group is used to structure different types of completions with different
@@ -21,6 +21,7 @@ type group struct {
 	descriptionsWidth []int         // Computed width for each column of completions, when aliases
 	listSeparator     string        // This is used to separate completion candidates from their descriptions.
 	list              bool          // Force completions to be listed instead of grided
+	horizontal        bool          // Fill the grid row by row (row-major) instead of column by column.
 	noSort            bool          // Don't sort completions
 	aliased           bool          // Are their aliased completions
 	preserveEscapes   bool          // Preserve escape sequences in the completion inserted values.
@@ -29,6 +30,17 @@ type group struct {
 	longestDesc       int           // Used to know how much descriptions can use when there are aliases.
 	maxDescAllowed    int           // Maximum ALLOWED description width.
 	termWidth         int           // Term size queried at beginning of computes by the engine.
+	menuWidth         int           // Columns available to the completions, at which messages wrap.
+	messages          []Message     // Messages attached to the group tag, displayed below its heading.
+	descLines         int           // Maximum number of rows used by descriptions, when listed (0 means no limit).
+	expandSelected    bool          // Only the description of the selected candidate is shown in full.
+	columnsX          []int         // Terminal columns at which each column starts, when displayed.
+
+	// Tree display
+	treeSep      string          // Separator of path segments, if the group is displayed as a tree.
+	tree         []*treeNode     // The roots of the tree.
+	treeRows     []*treeNode     // The visible nodes, one per row.
+	treeExpanded map[string]bool // Expanded nodes, by path.
 
 	// Selectors (position/bounds) management
 	posX int
@@ -45,18 +57,28 @@ func (e *Engine) newCompletionGroup(comps Values, tag string, vals RawValues, de
 		posX:         -1,
 		posY:         -1,
 		columnsWidth: []int{0},
-		termWidth:    term.GetWidth(),
+		termWidth:    e.displayWidth(),
+		menuWidth:    e.menuWidth(),
 		longestDesc:  longest(descriptions, true),
 	}
 
 	// Initialize all options for the group.
 	grp.initOptions(e, &comps, tag, vals)
 
+	if tag != "" {
+		grp.messages = comps.Messages.Tagged(tag)
+	}
+
 	// Global actions to take on all values.
 	if !grp.noSort {
 		sort.Stable(vals)
 	}
 
+	// Most frequently and recently accepted candidates come first.
+	if !grp.noSort && e.ranking != nil {
+		e.ranking.Sort(e.command(), vals)
+	}
+
 	// Initial processing of our assigned values:
 	// Compute color/no-color sizes, some max/min, etc.
 	grp.prepareValues(vals)
@@ -64,15 +86,42 @@ func (e *Engine) newCompletionGroup(comps Values, tag string, vals RawValues, de
 	// Generate the full grid of completions.
 	// Special processing is needed when some values
 	// share a common description, they are "aliased".
-	if completionsAreAliases(vals) {
+	switch {
+	case grp.treeSep != "" && len(vals) > 1:
+		grp.initCompletionsTree(vals, e.prefix)
+	case completionsAreAliases(vals):
 		grp.initCompletionAliased(vals)
-	} else {
+	default:
 		grp.initCompletionsGrid(vals)
 	}
 
 	e.groups = append(e.groups, grp)
 }
 
+// headerRows returns the number of rows used by the group heading and messages.
+// Messages are printed as is, so they wrap at the width of the completions menu.
+func (g *group) headerRows() int {
+	var rows int
+	if g.tag != "" {
+		rows++
+	}
+
+	for _, msg := range g.messages {
+		rows += messageRows(msg.Text, g.menuWidth)
+	}
+
+	return rows
+}
+
+// messageRows returns the number of terminal rows spanned by a message text.
+func messageRows(text string, width int) (rows int) {
+	for _, line := range strings.Split(text, "\n") {
+		rows += max(1, (strutil.RealLength(line)+width-1)/max(1, width))
+	}
+
+	return rows
+}
+
 // initOptions checks for global or group-specific options (display, behavior, grouping, etc).
 func (g *group) initOptions(eng *Engine, comps *Values, tag string, vals RawValues) {
 	// Override grid/list displays
@@ -115,6 +164,34 @@ func (g *group) initOptions(eng *Engine, comps *Values, tag string, vals RawValu
 	if noSort, all := comps.NoSort["*"]; noSort && all && len(comps.NoSort) == 1 {
 		g.noSort = true
 	}
+
+	// Grid layout: row-major or column-major, the group setting
+	// having precedence over the global completion option.
+	g.horizontal = eng.config.GetBool("print-completions-horizontally")
+	if horizontal, found := comps.Horizontal[tag]; found {
+		g.horizontal = horizontal
+	} else if horizontal, found := comps.Horizontal["*"]; found {
+		g.horizontal = horizontal
+	}
+
+	// A zero display width means one candidate per line.
+	if eng.config.GetInt("completion-display-width") == 0 {
+		g.list = true
+	}
+
+	// Listed descriptions can be wrapped on several rows.
+	g.descLines = max(0, eng.config.GetInt("completion-description-lines"))
+
+	g.expandSelected = comps.ExpandDesc[tag]
+	if expand, all := comps.ExpandDesc["*"]; expand && all {
+		g.expandSelected = true
+	}
+
+	// Path-like candidates can be displayed as a tree.
+	g.treeSep = comps.Tree[tag]
+	if sep, all := comps.Tree["*"]; all && g.treeSep == "" {
+		g.treeSep = sep
+	}
 }
 
 // initCompletionsGrid arranges completions when there are no aliases.
@@ -133,7 +210,12 @@ func (g *group) initCompletionsGrid(comps RawValues) {
 
 	rowCount := int(math.Ceil(float64(len(comps)) / (float64(maxColumns))))
 
-	g.rows = createGrid(comps, rowCount, maxColumns)
+	if g.horizontal {
+		g.rows = createGrid(comps, rowCount, maxColumns)
+	} else {
+		g.rows = createGridVertical(comps, rowCount)
+	}
+
 	g.calculateMaxColumnWidths(g.rows)
 }
 
@@ -542,6 +624,12 @@ func (g *group) moveSelector(x, y int) (done, next bool) {
 			return g.findFirstCandidate(x, y)
 		}
 
+		// Column-major grids might have a shorter last column:
+		// moving down past its last candidate ends the group.
+		if !g.horizontal && y > 0 {
+			return true, true
+		}
+
 		g.posX = 0
 
 		if g.posY < g.maxY-1 {
@@ -599,9 +687,17 @@ func (g *group) lastCell() {
 	g.posY = len(g.rows) - 1
 	g.posX = len(g.columnsWidth) - 1
 
-	if g.aliased {
+	switch {
+	case g.aliased:
 		g.findFirstCandidate(0, -1)
-	} else {
+	case !g.horizontal:
+		// The last candidate is at the bottom of the last column,
+		// which is the only one that might not span all rows.
+		g.posX = len(g.rows[0]) - 1
+		for g.posY > 0 && len(g.rows[g.posY])-1 < g.posX {
+			g.posY--
+		}
+	default:
 		g.posX = len(g.rows[g.posY]) - 1
 	}
 }
@@ -638,6 +734,23 @@ func createGrid(values []Candidate, rowCount, maxColumns int) [][]Candidate {
 	return grid
 }
 
+// createGridVertical fills the grid column by column (column-major), so that
+// candidates are read top to bottom, then left to right. Only the last column
+// might be shorter than the others.
+func createGridVertical(values []Candidate, rowCount int) [][]Candidate {
+	if rowCount <= 0 {
+		return make([][]Candidate, 0)
+	}
+
+	grid := make([][]Candidate, rowCount)
+
+	for i, value := range values {
+		grid[i%rowCount] = append(grid[i%rowCount], value)
+	}
+
+	return grid
+}
+
 func createRow(domains []Candidate, maxColumns, rowIndex int) []Candidate {
 	rowStart := rowIndex * maxColumns
 	rowEnd := (rowIndex + 1) * maxColumns
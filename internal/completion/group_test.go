@@ -0,0 +1,167 @@
+package completion
+
+import (
+	"testing"
+)
+
+func candidates(values ...string) []Candidate {
+	comps := make([]Candidate, 0, len(values))
+	for _, val := range values {
+		comps = append(comps, Candidate{Value: val, Display: val})
+	}
+
+	return comps
+}
+
+func gridValues(grid [][]Candidate) [][]string {
+	rows := make([][]string, 0, len(grid))
+
+	for _, row := range grid {
+		values := make([]string, 0, len(row))
+		for _, comp := range row {
+			values = append(values, comp.Value)
+		}
+
+		rows = append(rows, values)
+	}
+
+	return rows
+}
+
+func TestCreateGrid(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     []string
+		rowCount   int
+		maxColumns int
+		horizontal bool
+		want       [][]string
+	}{
+		{
+			name:       "Row-major full grid",
+			values:     []string{"a", "b", "c", "d"},
+			rowCount:   2,
+			maxColumns: 2,
+			horizontal: true,
+			want:       [][]string{{"a", "b"}, {"c", "d"}},
+		},
+		{
+			name:       "Row-major, shorter last row",
+			values:     []string{"a", "b", "c", "d", "e"},
+			rowCount:   2,
+			maxColumns: 3,
+			horizontal: true,
+			want:       [][]string{{"a", "b", "c"}, {"d", "e"}},
+		},
+		{
+			name:       "Column-major full grid",
+			values:     []string{"a", "b", "c", "d"},
+			rowCount:   2,
+			maxColumns: 2,
+			want:       [][]string{{"a", "c"}, {"b", "d"}},
+		},
+		{
+			name:       "Column-major, shorter last column",
+			values:     []string{"a", "b", "c", "d", "e"},
+			rowCount:   2,
+			maxColumns: 3,
+			want:       [][]string{{"a", "c", "e"}, {"b", "d"}},
+		},
+		{
+			name:       "Column-major, single column",
+			values:     []string{"a", "b", "c"},
+			rowCount:   3,
+			maxColumns: 1,
+			want:       [][]string{{"a"}, {"b"}, {"c"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var grid [][]Candidate
+			if tt.horizontal {
+				grid = createGrid(candidates(tt.values...), tt.rowCount, tt.maxColumns)
+			} else {
+				grid = createGridVertical(candidates(tt.values...), tt.rowCount)
+			}
+
+			got := gridValues(grid)
+			if len(got) != len(tt.want) {
+				t.Fatalf("createGrid() rows = %v, want %v", got, tt.want)
+			}
+
+			for i := range got {
+				if len(got[i]) != len(tt.want[i]) {
+					t.Fatalf("createGrid() = %v, want %v", got, tt.want)
+				}
+
+				for j := range got[i] {
+					if got[i][j] != tt.want[i][j] {
+						t.Errorf("createGrid() = %v, want %v", got, tt.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGroupMoveSelector(t *testing.T) {
+	tests := []struct {
+		name       string
+		horizontal bool
+		x, y       int
+		want       []string
+	}{
+		{
+			name:       "Row-major cycling",
+			horizontal: true,
+			x:          1,
+			want:       []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name: "Column-major cycling",
+			y:    1,
+			want: []string{"a", "b", "c", "d", "e"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grp := &group{horizontal: tt.horizontal, posX: -1, posY: -1}
+
+			// Visually, the grid is "a c e / b d" in column-major,
+			// and "a b c / d e" in row-major, but it must be cycled
+			// in the same order as the candidates were sorted.
+			values := candidates("a", "b", "c", "d", "e")
+			if tt.horizontal {
+				grp.rows = createGrid(values, 2, 3)
+			} else {
+				grp.rows = createGridVertical(values, 2)
+			}
+
+			grp.maxY = len(grp.rows)
+			grp.maxX = len(grp.rows[0])
+
+			for i, want := range tt.want {
+				done, _ := grp.moveSelector(tt.x, tt.y)
+				if done {
+					t.Fatalf("moveSelector() done too early at step %d", i)
+				}
+
+				if got := grp.selected().Value; got != want {
+					t.Errorf("moveSelector() step %d selected %q, want %q", i, got, want)
+				}
+			}
+
+			if done, next := grp.moveSelector(tt.x, tt.y); !done || !next {
+				t.Errorf("moveSelector() past last candidate: done = %v, next = %v", done, next)
+			}
+
+			grp.lastCell()
+
+			if got := grp.selected().Value; got != "e" {
+				t.Errorf("lastCell() selected %q, want %q", got, "e")
+			}
+		})
+	}
+}
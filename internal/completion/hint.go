@@ -1,6 +1,7 @@
 package completion
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/reeflective/readline/internal/color"
@@ -12,20 +13,22 @@ func (e *Engine) hintCompletions(comps Values) {
 
 	// First add the command/flag usage string if any,
 	// and only if we don't have completions.
-	if len(comps.values) == 0 || e.config.GetBool("usage-hint-always") {
-		if comps.Usage != "" {
-			hint += color.Dim + comps.Usage + color.Reset + term.NewlineReturn
-		}
+	showUsage := len(comps.values) == 0 || e.config.GetBool("usage-hint-always")
+
+	if showUsage && comps.Usage != "" {
+		hint += e.renderMessage(Message{Text: comps.Usage, Level: MessageUsage}) + term.NewlineReturn
 	}
 
-	// Add application-specific messages.
+	// Add application-specific messages, styled according to their level.
 	// There is full support for color in them, but in case those messages
 	// don't include any, we tame the color a little bit first, like hints.
-	messages := strings.Join(comps.Messages.Get(), term.NewlineReturn)
-	messages = strings.TrimSuffix(messages, term.NewlineReturn)
+	// Messages attached to a tag are displayed along with their group.
+	for _, msg := range e.hintMessages(comps.Messages) {
+		if msg.Level == MessageUsage && !showUsage {
+			continue
+		}
 
-	if messages != "" {
-		hint = hint + color.Dim + messages
+		hint += e.renderMessage(msg) + term.NewlineReturn
 	}
 
 	// If we don't have any completions, and no messages, let's say it.
@@ -42,6 +45,20 @@ func (e *Engine) hintCompletions(comps Values) {
 	e.hint.Set(hint + color.Reset)
 }
 
+// hintMessages returns the messages to display in the hint: global ones,
+// and those attached to tags for which there is no completion group.
+func (e *Engine) hintMessages(messages Messages) []Message {
+	hint := messages.Global()
+
+	for _, tag := range messages.tags() {
+		if !slices.ContainsFunc(e.groups, func(grp *group) bool { return grp.tag == tag }) {
+			hint = append(hint, messages.Tagged(tag)...)
+		}
+	}
+
+	return hint
+}
+
 func (e *Engine) hintNoMatches() string {
 	noMatches := color.Dim + "no matching"
 
@@ -1,6 +1,7 @@
 package completion
 
 import (
+	"slices"
 	"unicode"
 
 	"github.com/reeflective/readline/inputrc"
@@ -57,6 +58,13 @@ func (e *Engine) TrimSuffix() {
 	keys := e.keys.Caller()
 	key := keys[0]
 
+	// Only suffixes matched by the matcher can be removed: this is
+	// notably not the case of closing quotes of quoted candidates.
+	if !e.sm.Matches(string(suf)) {
+		e.sm = SuffixMatcher{}
+		return
+	}
+
 	// Special case when completing paths: if the comp is ended
 	// by a slash, only remove this slash if the inserted key is
 	// one of the suffix matchers, otherwise keep it.
@@ -109,23 +117,58 @@ func (e *Engine) acceptCandidate() {
 	}
 
 	e.selected = cur.selected()
+	accepted := e.acceptedCandidates()
+
+	for _, comp := range accepted {
+		e.recordAccepted(comp)
+	}
 
 	// Prepare the completion candidate, remove the
 	// prefix part and save its sufffixes for later.
 	completion := e.prepareSuffix()
 	e.inserted = []rune(completion)
 
+	// When completing in the middle of a word, don't
+	// duplicate text already typed after the cursor.
+	if skip := e.skipCompletedText(e.inserted); skip > 0 {
+		e.line.Cut(e.cursor.Pos(), e.cursor.Pos()+skip)
+	}
+
 	// Remove the line prefix and insert the candidate.
 	e.cursor.Move(-1 * len(e.prefix))
 	e.line.Cut(e.cursor.Pos(), e.cursor.Pos()+len(e.prefix))
 	e.cursor.InsertAt(e.inserted...)
 
+	for _, comp := range accepted {
+		e.runAcceptAction(comp)
+	}
+
 	// And forget about this inserted completion.
 	e.inserted = make([]rune, 0)
 	e.prefix = ""
 	e.suffix = ""
 }
 
+// runAcceptAction runs the accept action of a candidate that has just
+// become part of the real input line, and applies the changes it made.
+// Completions are generated again if any of the accepted candidates asks for it.
+func (e *Engine) runAcceptAction(comp Candidate) {
+	if comp.Accept == nil {
+		return
+	}
+
+	line, cursor, complete := comp.Accept(slices.Clone(*e.line), e.cursor.Pos())
+
+	// The suffix matcher is only valid for an unchanged line.
+	if string(line) != string(*e.line) || cursor != e.cursor.Pos() {
+		e.sm = SuffixMatcher{}
+	}
+
+	e.line.Set(line...)
+	e.cursor.Set(cursor)
+	e.followUp = e.followUp || complete
+}
+
 // insertCandidate inserts a completion candidate into the virtual (completed) line.
 func (e *Engine) insertCandidate() {
 	grp := e.currentGroup()
@@ -165,6 +208,11 @@ func (e *Engine) prepareSuffix() (comp string) {
 		return
 	}
 
+	// Marked candidates are all inserted together.
+	if len(e.marked) > 0 {
+		return e.joinMarked()
+	}
+
 	comp = e.selected.Value
 	prefix := len(e.prefix)
 
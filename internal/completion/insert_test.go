@@ -0,0 +1,78 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/reeflective/readline/inputrc"
+	"github.com/reeflective/readline/internal/core"
+)
+
+func TestEngineAcceptAction(t *testing.T) {
+	tests := []struct {
+		name         string
+		line         string
+		prefix       string
+		comp         Candidate
+		wantLine     string
+		wantCursor   int
+		wantFollowUp bool
+	}{
+		{
+			name:       "No accept action",
+			line:       "git commit --mes",
+			prefix:     "--mes",
+			comp:       Candidate{Value: "--message"},
+			wantLine:   "git commit --message",
+			wantCursor: 20,
+		},
+		{
+			name:   "Cursor placed inside snippet quotes",
+			line:   "git commit --mes",
+			prefix: "--mes",
+			comp: Candidate{Value: `--message=""`, Accept: func(line []rune, cursor int) ([]rune, int, bool) {
+				return line, cursor - 1, false
+			}},
+			wantLine:   `git commit --message=""`,
+			wantCursor: 22,
+		},
+		{
+			name:   "Required flag pair and follow-up completion",
+			line:   "tar -",
+			prefix: "-",
+			comp: Candidate{Value: "-x", Accept: func(line []rune, cursor int) ([]rune, int, bool) {
+				line = append(line, []rune(" -f ")...)
+				return line, len(line), true
+			}},
+			wantLine:     "tar -x -f ",
+			wantCursor:   10,
+			wantFollowUp: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := core.Line([]rune(tt.line))
+			eng := &Engine{config: inputrc.NewDefaultConfig(), line: &line, cursor: core.NewCursor(&line), prefix: tt.prefix}
+			eng.cursor.Set(line.Len())
+			eng.groups = []*group{{rows: [][]Candidate{{tt.comp}}, posX: -1, posY: -1}}
+
+			eng.acceptCandidate()
+
+			if string(line) != tt.wantLine {
+				t.Errorf("acceptCandidate() line = %q, want %q", string(line), tt.wantLine)
+			}
+
+			if eng.cursor.Pos() != tt.wantCursor {
+				t.Errorf("acceptCandidate() cursor = %d, want %d", eng.cursor.Pos(), tt.wantCursor)
+			}
+
+			if got := eng.FollowUp(); got != tt.wantFollowUp {
+				t.Errorf("FollowUp() = %v, want %v", got, tt.wantFollowUp)
+			}
+
+			if eng.FollowUp() {
+				t.Errorf("FollowUp() not reset after being read")
+			}
+		})
+	}
+}
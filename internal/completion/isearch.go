@@ -20,6 +20,8 @@ func (e *Engine) IsearchStart(name string, autoinsert, replaceLine bool) {
 
 	e.isearchBuf = new(core.Line)
 	e.isearchCur = core.NewCursor(e.isearchBuf)
+	e.isearchPrevBuf = ""
+	e.isearchFound = true
 
 	// Prepare all keymaps and modes.
 	e.auto = true
@@ -57,6 +59,17 @@ func (e *Engine) IsearchStop(revertLine bool) {
 	e.resetIsearchInsertMode()
 }
 
+// IsearchLineRegex returns the current search regex when the input line
+// is replaced with the search results, so that its matches can be
+// highlighted in the line. Otherwise, it returns nil.
+func (e *Engine) IsearchLineRegex() *regexp.Regexp {
+	if !e.isearchReplaceLine {
+		return nil
+	}
+
+	return e.IsearchRegex
+}
+
 // GetBuffer returns the correct input line buffer (and its cursor/
 // selection) depending on the context and active components:
 // - If in non/incremental-search mode, the minibuffer.
@@ -182,6 +195,8 @@ func (e *Engine) updateIncrementalSearch() {
 		isearchHint += color.Reset + color.Bold + color.FgRed + " (no matches)"
 	}
 
+	e.ringIsearchFailed()
+
 	isearchHint += ": " + color.Reset + color.Bold + string(*e.isearchBuf) + color.Reset + "_"
 
 	e.hint.Set(isearchHint)
@@ -203,6 +218,24 @@ func (e *Engine) updateIncrementalSearch() {
 	}
 }
 
+// ringIsearchFailed rings the bell when the minibuffer has just been
+// changed and does not match anything anymore, but not on other keys.
+func (e *Engine) ringIsearchFailed() {
+	found := e.Matches() > 0
+	changed := string(*e.isearchBuf) != e.isearchPrevBuf
+
+	if !changed {
+		return
+	}
+
+	if e.isearchFound && !found && e.isearchBuf.Len() > 0 {
+		e.bell.Ring()
+	}
+
+	e.isearchPrevBuf = string(*e.isearchBuf)
+	e.isearchFound = found
+}
+
 func (e *Engine) updateNonIncrementalSearch() {
 	isearchHint := color.Bold + color.FgCyan + e.isearchName +
 		" (non-inc-search): " + color.Reset + color.Bold + string(*e.isearchBuf) + color.Reset + "_"
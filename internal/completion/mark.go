@@ -0,0 +1,112 @@
+package completion
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/reeflective/readline/internal/color"
+	"github.com/reeflective/readline/internal/strutil"
+)
+
+// ToggleMark marks the currently selected candidate if it is not marked yet,
+// or unmarks it otherwise. As long as some candidates are marked, all of them
+// are inserted in the line together (in the order they were marked), instead
+// of the currently selected candidate only.
+func (e *Engine) ToggleMark() {
+	grp := e.currentGroup()
+	if grp == nil || len(grp.rows) == 0 {
+		return
+	}
+
+	comp := grp.selected()
+	comp.noSpace = grp.noSpace
+
+	// If we already have an inserted candidate
+	// remove it before inserting the new one(s).
+	if len(e.selected.Value) > 0 {
+		e.cancelCompletedLine()
+	}
+
+	defer e.insertCandidate()
+
+	for i, marked := range e.marked {
+		if marked.Value == comp.Value && marked.Tag == comp.Tag {
+			e.marked = append(e.marked[:i], e.marked[i+1:]...)
+			return
+		}
+	}
+
+	e.marked = append(e.marked, comp)
+}
+
+// acceptedCandidates returns the candidates being accepted into the line:
+// all marked candidates if any, or the currently selected candidate.
+func (e *Engine) acceptedCandidates() []Candidate {
+	if len(e.marked) > 0 {
+		return slices.Clone(e.marked)
+	}
+
+	return []Candidate{e.selected}
+}
+
+// Marked returns the number of currently marked candidates.
+func (e *Engine) Marked() int {
+	return len(e.marked)
+}
+
+// isMarked returns true if the candidate of the given group is marked.
+func (e *Engine) isMarked(grp *group, comp Candidate) bool {
+	if len(e.marked) == 0 || comp.Value == "" {
+		return false
+	}
+
+	value := comp.Value
+	if !grp.preserveEscapes {
+		value = color.Strip(value)
+	}
+
+	for _, marked := range e.marked {
+		if marked.Value == value && marked.Tag == comp.Tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// joinMarked returns all marked candidates quoted and joined with spaces.
+// Candidates already escaped for the current word (see Values.Quote) are
+// kept as is, so that all of them use the same quoting as the word.
+// The suffix of each candidate but the last one is trimmed when matched by
+// its suffix matcher, as it would be if a space was typed after it.
+// The last candidate suffix matcher is kept for later removal, as usual.
+func (e *Engine) joinMarked() string {
+	words := make([]string, 0, len(e.marked))
+
+	for i, comp := range e.marked {
+		value := comp.Value
+		if i < len(e.marked)-1 {
+			value = comp.noSpace.trim(value)
+		}
+
+		if !comp.quoted {
+			value = strutil.QuoteWord(value)
+		}
+
+		words = append(words, value)
+	}
+
+	joined := strings.Join(words, " ")
+
+	// A quoted candidate ends with a quote, not a suffix.
+	last := e.marked[len(e.marked)-1]
+	if words[len(words)-1] != last.Value {
+		e.sm = SuffixMatcher{}
+		return joined
+	}
+
+	e.sm = last.noSpace
+	e.sm.pos = e.cursor.Pos() + len([]rune(joined)) - len([]rune(e.prefix)) - 1
+
+	return joined
+}
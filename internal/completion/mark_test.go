@@ -0,0 +1,88 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/reeflective/readline/internal/core"
+)
+
+func TestEngineJoinMarked(t *testing.T) {
+	tests := []struct {
+		name    string
+		marked  []Candidate
+		noSpace string
+		prefix  string
+		want    string
+		wantSM  string
+		wantPos int
+	}{
+		{
+			name:   "Single candidate",
+			marked: candidates("file.go"),
+			want:   "file.go",
+		},
+		{
+			name:   "Several candidates, quoted",
+			marked: candidates("file.go", "my file.go", "it's.go"),
+			want:   `file.go 'my file.go' "it's.go"`,
+		},
+		{
+			name:   "Candidates escaped for the word",
+			marked: AddRaw(candidates("my file.go", "my fine.go")).Quote(`my\ fi`, "my fi", 0, false).values,
+			want:   `my\ file.go my\ fine.go`,
+		},
+		{
+			name:   "Candidates escaped in open quotes",
+			marked: AddRaw(candidates("my file.go", "it's.go")).Quote(`'`, "", '\'', true).values,
+			want:   `'my file.go' 'it'\''s.go'`,
+		},
+		{
+			name:    "Suffixes trimmed except on last candidate",
+			marked:  candidates("dir/", "other/", "last/"),
+			noSpace: "/",
+			want:    "dir other last/",
+			wantSM:  "/",
+		},
+		{
+			name:    "Non-ASCII candidates",
+			marked:  candidates("résumé/", "réseau/"),
+			noSpace: "/",
+			prefix:  "ré",
+			want:    "résumé réseau/",
+			wantSM:  "/",
+			wantPos: 16,
+		},
+		{
+			name:    "Wildcard suffix never trims",
+			marked:  candidates("a,", "b,"),
+			noSpace: "*",
+			want:    "a, b,",
+			wantSM:  "*",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := core.Line([]rune("ls " + tt.prefix))
+			eng := &Engine{line: &line, cursor: core.NewCursor(&line), prefix: tt.prefix}
+			eng.cursor.Set(line.Len())
+
+			for _, comp := range tt.marked {
+				comp.noSpace.Add([]rune(tt.noSpace)...)
+				eng.marked = append(eng.marked, comp)
+			}
+
+			if got := eng.joinMarked(); got != tt.want {
+				t.Errorf("joinMarked() = %q, want %q", got, tt.want)
+			}
+
+			if eng.sm.string != tt.wantSM {
+				t.Errorf("joinMarked() suffix matcher = %q, want %q", eng.sm.string, tt.wantSM)
+			}
+
+			if tt.wantPos > 0 && eng.sm.pos != tt.wantPos {
+				t.Errorf("joinMarked() suffix matcher position = %d, want %d", eng.sm.pos, tt.wantPos)
+			}
+		})
+	}
+}
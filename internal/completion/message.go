@@ -2,19 +2,42 @@ package completion
 
 import (
 	"regexp"
+	"slices"
 	"sort"
+
+	"github.com/reeflective/readline/internal/color"
+)
+
+// MessageLevel is the severity of a completion message, which determines its style.
+type MessageLevel int
+
+// Completion message levels, from the least to the most severe.
+const (
+	MessageInfo MessageLevel = iota
+	MessageUsage
+	MessageWarning
+	MessageError
 )
 
+// Message is a completion message, displayed either below the input line or,
+// if it has a tag, below the heading of the completion group with this tag.
+type Message struct {
+	Text  string
+	Level MessageLevel
+	Tag   string
+	Async bool // The message was produced by a completer running in the background.
+}
+
 // Messages is a list of messages to be displayed
 // below the input line, above completions. It is
 // used to show usage and/or error status hints.
 type Messages struct {
-	messages map[string]bool
+	messages map[Message]bool
 }
 
 func (m *Messages) init() {
 	if m.messages == nil {
-		m.messages = make(map[string]bool)
+		m.messages = make(map[Message]bool)
 	}
 }
 
@@ -24,24 +47,87 @@ func (m Messages) IsEmpty() bool {
 	return len(m.messages) == 0
 }
 
-// Add adds a message to the list of messages.
+// Add adds an informational message to the list of messages.
 func (m *Messages) Add(s string) {
+	m.AddMessage(Message{Text: s})
+}
+
+// AddMessage adds a message with its level and tag to the list of messages.
+func (m *Messages) AddMessage(msg Message) {
 	m.init()
-	m.messages[s] = true
+	m.messages[msg] = true
 }
 
-// Get returns the list of messages to display.
-func (m Messages) Get() []string {
-	messages := make([]string, 0)
+// All returns all messages, the most severe ones first, and sorted alphabetically.
+func (m Messages) All() []Message {
+	messages := make([]Message, 0, len(m.messages))
 	for message := range m.messages {
 		messages = append(messages, message)
 	}
 
-	sort.Strings(messages)
+	sortMessages(messages)
+
+	return messages
+}
+
+// Global returns the messages not attached to any tag,
+// the most severe ones first, and sorted alphabetically.
+func (m Messages) Global() []Message {
+	return m.Tagged("")
+}
+
+// Tagged returns the messages attached to a completion tag,
+// the most severe ones first, and sorted alphabetically.
+func (m Messages) Tagged(tag string) []Message {
+	messages := make([]Message, 0)
+
+	for message := range m.messages {
+		if message.Tag == tag {
+			messages = append(messages, message)
+		}
+	}
+
+	sortMessages(messages)
 
 	return messages
 }
 
+func sortMessages(messages []Message) {
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].Level != messages[j].Level {
+			return messages[i].Level > messages[j].Level
+		}
+
+		return messages[i].Text < messages[j].Text
+	})
+}
+
+// tags returns the tags to which at least one message is attached.
+func (m Messages) tags() []string {
+	var tags []string
+
+	for message := range m.messages {
+		if message.Tag != "" && !slices.Contains(tags, message.Tag) {
+			tags = append(tags, message.Tag)
+		}
+	}
+
+	sort.Strings(tags)
+
+	return tags
+}
+
+// HasErrors returns true if there is at least one error message.
+func (m Messages) HasErrors() bool {
+	for message := range m.messages {
+		if message.Level == MessageError {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Suppress removes messages matching the given regular expressions from the list of messages.
 func (m *Messages) Suppress(expr ...string) error {
 	m.init()
@@ -53,7 +139,7 @@ func (m *Messages) Suppress(expr ...string) error {
 		}
 
 		for key := range m.messages {
-			if char.MatchString(key) {
+			if char.MatchString(key.Text) {
 				delete(m.messages, key)
 			}
 		}
@@ -69,6 +155,38 @@ func (m *Messages) Merge(other Messages) {
 	}
 
 	for key := range other.messages {
-		m.Add(key)
+		m.AddMessage(key)
+	}
+}
+
+// markAsync marks all messages as produced by a completer running in the background.
+func (m *Messages) markAsync() {
+	messages := make(map[Message]bool, len(m.messages))
+
+	for key := range m.messages {
+		key.Async = true
+		messages[key] = true
 	}
+
+	m.messages = messages
+}
+
+// renderMessage returns a message styled according to its level.
+func (e *Engine) renderMessage(msg Message) string {
+	var style string
+
+	switch {
+	case msg.Async && msg.Level == MessageError:
+		style = e.config.GetString("completion-async-error-style")
+	case msg.Level == MessageError:
+		style = e.config.GetString("completion-error-style")
+	case msg.Level == MessageWarning:
+		style = e.config.GetString("completion-warning-style")
+	case msg.Level == MessageUsage:
+		style = e.config.GetString("completion-usage-style")
+	default:
+		style = e.config.GetString("completion-info-style")
+	}
+
+	return color.UnquoteRC(style) + msg.Text + color.Reset
 }
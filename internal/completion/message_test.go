@@ -0,0 +1,103 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/reeflective/readline/inputrc"
+)
+
+func TestMessagesTagged(t *testing.T) {
+	var messages Messages
+
+	messages.Add("b info")
+	messages.Add("a info")
+	messages.AddMessage(Message{Text: "z error", Level: MessageError})
+	messages.AddMessage(Message{Text: "warning", Level: MessageWarning})
+	messages.AddMessage(Message{Text: "unreachable", Level: MessageWarning, Tag: "remotes"})
+
+	tests := []struct {
+		name string
+		tag  string
+		want []string
+	}{
+		{
+			name: "Global messages, most severe first",
+			want: []string{"z error", "warning", "a info", "b info"},
+		},
+		{
+			name: "Tagged messages",
+			tag:  "remotes",
+			want: []string{"unreachable"},
+		},
+		{
+			name: "No messages for tag",
+			tag:  "branches",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := messages.Tagged(tt.tag)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Tagged(%q) = %v, want %v", tt.tag, got, tt.want)
+			}
+
+			for i, msg := range got {
+				if msg.Text != tt.want[i] {
+					t.Errorf("Tagged(%q)[%d] = %q, want %q", tt.tag, i, msg.Text, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if err := messages.Suppress("^warning$"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := messages.Global(); len(got) != 3 {
+		t.Errorf("Global() after Suppress() = %v, want 3 messages", got)
+	}
+
+	if !messages.HasErrors() {
+		t.Errorf("HasErrors() = false, want true")
+	}
+}
+
+func TestEngineRenderMessage(t *testing.T) {
+	config := inputrc.NewDefaultConfig()
+	config.Set("completion-error-style", "\x1b[31m")
+	config.Set("completion-async-error-style", "\x1b[2;31m")
+	config.Set("completion-info-style", "\x1b[2m")
+
+	eng := &Engine{config: config}
+
+	tests := []struct {
+		name string
+		msg  Message
+		want string
+	}{
+		{
+			name: "Error",
+			msg:  Message{Text: "failed", Level: MessageError},
+			want: "\x1b[31mfailed\x1b[0m",
+		},
+		{
+			name: "Async error",
+			msg:  Message{Text: "failed", Level: MessageError, Async: true},
+			want: "\x1b[2;31mfailed\x1b[0m",
+		},
+		{
+			name: "Async info",
+			msg:  Message{Text: "loading", Level: MessageInfo, Async: true},
+			want: "\x1b[2mloading\x1b[0m",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eng.renderMessage(tt.msg); got != tt.want {
+				t.Errorf("renderMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package completion
+
+// SelectAt selects the candidate displayed on the given completion row and
+// terminal column (both starting at 0, the first row being the first one
+// printed below the hint), and virtually inserts it in the line.
+// Returns false if there is no candidate at those coordinates.
+func (e *Engine) SelectAt(row, column int) bool {
+	grp, posY, posX, found := e.candidateAt(row, column)
+	if !found {
+		return false
+	}
+
+	e.adjustSelectKeymap()
+
+	if len(e.selected.Value) > 0 {
+		e.cancelCompletedLine()
+	}
+
+	for _, g := range e.groups {
+		g.isCurrent = false
+	}
+
+	grp.isCurrent = true
+	grp.posY = posY
+	grp.posX = posX
+
+	e.insertCandidate()
+
+	return true
+}
+
+// candidateAt returns the group and coordinates of the candidate
+// displayed at a given row (accounting for the rows cropped above)
+// and column of the completions.
+func (e *Engine) candidateAt(row, column int) (grp *group, posY, posX int, found bool) {
+	line := row + e.scrollY
+
+	// The preview pane might be displayed on the right.
+	if column >= e.menuWidth() {
+		return nil, 0, 0, false
+	}
+
+	for _, grp = range e.groups {
+		if len(grp.rows) == 0 {
+			continue
+		}
+
+		line -= grp.headerRows()
+		if line < 0 {
+			return nil, 0, 0, false
+		}
+
+		for posY = range grp.rows {
+			if line < grp.rowHeight(posY) {
+				posX = grp.columnAt(posY, column)
+				return grp, posY, posX, posX >= 0
+			}
+
+			line -= grp.rowHeight(posY)
+		}
+	}
+
+	return nil, 0, 0, false
+}
+
+// columnAt returns the index of the candidate displayed
+// at a terminal column in a row, or -1 if there is none.
+func (g *group) columnAt(row, column int) int {
+	index := -1
+
+	for col, start := range g.columnsX {
+		if start > column {
+			break
+		}
+
+		index = col
+	}
+
+	if index >= len(g.rows[row]) || g.rows[row][max(index, 0)].Display == "" {
+		return -1
+	}
+
+	return index
+}
@@ -0,0 +1,40 @@
+package completion
+
+import "testing"
+
+func TestEngineCandidateAt(t *testing.T) {
+	files := &group{tag: "files", rows: [][]Candidate{candidates("a", "b"), candidates("c")}, columnsX: []int{0, 10}}
+	other := &group{rows: [][]Candidate{candidates("d")}, columnsX: []int{0}}
+	eng := &Engine{groups: []*group{files, other}}
+
+	tests := []struct {
+		name      string
+		scrollY   int
+		row, col  int
+		want      string
+		wantFound bool
+	}{
+		{name: "Group heading", row: 0, col: 0},
+		{name: "First column", row: 1, col: 3, want: "a", wantFound: true},
+		{name: "Second column", row: 1, col: 12, want: "b", wantFound: true},
+		{name: "Empty cell", row: 2, col: 12},
+		{name: "Group without heading", row: 3, col: 0, want: "d", wantFound: true},
+		{name: "Past the last row", row: 4, col: 0},
+		{name: "Cropped rows", scrollY: 1, row: 0, col: 0, want: "a", wantFound: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			eng.scrollY = test.scrollY
+
+			grp, posY, posX, found := eng.candidateAt(test.row, test.col)
+			if found != test.wantFound {
+				t.Fatalf("candidateAt() found = %v, want %v", found, test.wantFound)
+			}
+
+			if found && grp.rows[posY][posX].Value != test.want {
+				t.Errorf("candidateAt() = %q, want %q", grp.rows[posY][posX].Value, test.want)
+			}
+		})
+	}
+}
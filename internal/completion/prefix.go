@@ -0,0 +1,130 @@
+package completion
+
+import (
+	"strconv"
+
+	"github.com/reeflective/readline/internal/color"
+)
+
+// CompleteCommonPrefix generates completions with a completer and, like bash's
+// complete command, either inserts the only matching candidate there is, or the
+// longest prefix common to all matching candidates. Completions are not displayed.
+// It returns the number of matching candidates, and whether the line was modified.
+func (e *Engine) CompleteCommonPrefix(completer Completer) (matches int, modified bool) {
+	e.ambiguous = ""
+
+	if completer == nil {
+		return 0, false
+	}
+
+	line, pos := string(*e.line), e.cursor.Pos()
+
+	e.prepare(completer())
+	defer e.ClearMenu(true)
+
+	matches = e.Matches()
+
+	switch {
+	case matches == 1:
+		e.acceptCandidate()
+	case matches > 1:
+		e.insertCommonPrefix()
+	}
+
+	modified = string(*e.line) != line || e.cursor.Pos() != pos
+
+	// Remember the line for which the completion is ambiguous,
+	// so that the next attempt on it can list the candidates.
+	if matches > 1 {
+		e.ambiguous = e.lineState()
+	}
+
+	return matches, modified
+}
+
+// Ambiguous returns true if the last common-prefix completion found several
+// candidates, and if the line and cursor have not changed since then.
+func (e *Engine) Ambiguous() bool {
+	return e.ambiguous != "" && e.ambiguous == e.lineState()
+}
+
+// insertCommonPrefix replaces the current completion prefix with the longest
+// prefix common to all candidates, if it is longer than the current one.
+func (e *Engine) insertCommonPrefix() {
+	var values []string
+
+	for _, grp := range e.groups {
+		for _, row := range grp.rows {
+			for _, comp := range row {
+				value := comp.Value
+				if !grp.preserveEscapes {
+					value = color.Strip(value)
+				}
+
+				values = append(values, value)
+			}
+		}
+	}
+
+	prefix := commonPrefix(values)
+	typed := len([]rune(e.prefix))
+
+	if len(prefix) <= typed {
+		return
+	}
+
+	e.cursor.Move(-1 * typed)
+	e.line.Cut(e.cursor.Pos(), e.cursor.Pos()+typed)
+	e.cursor.InsertAt(prefix...)
+	e.prefix = ""
+}
+
+// skipCompletedText returns the number of characters following the cursor
+// that are identical to those following the prefix in the completion,
+// and which should therefore not be duplicated when inserting it.
+func (e *Engine) skipCompletedText(completion []rune) int {
+	if !e.config.GetBool("skip-completed-text") {
+		return 0
+	}
+
+	prefix := len([]rune(e.prefix))
+	if prefix > len(completion) {
+		return 0
+	}
+
+	completion = completion[prefix:]
+	after := (*e.line)[e.cursor.Pos():]
+
+	var skip int
+	for skip < len(completion) && skip < len(after) && completion[skip] == after[skip] {
+		skip++
+	}
+
+	return skip
+}
+
+func (e *Engine) lineState() string {
+	return string(*e.line) + "\x00" + strconv.Itoa(e.cursor.Pos())
+}
+
+// commonPrefix returns the longest prefix shared by all values.
+func commonPrefix(values []string) []rune {
+	if len(values) == 0 {
+		return nil
+	}
+
+	prefix := []rune(values[0])
+
+	for _, value := range values[1:] {
+		runes := []rune(value)
+
+		var common int
+		for common < len(prefix) && common < len(runes) && prefix[common] == runes[common] {
+			common++
+		}
+
+		prefix = prefix[:common]
+	}
+
+	return prefix
+}
@@ -0,0 +1,78 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/reeflective/readline/internal/core"
+)
+
+func TestEngineInsertCommonPrefix(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		prefix     string
+		values     []string
+		wantLine   string
+		wantCursor int
+	}{
+		{
+			name:       "Common prefix longer than typed",
+			line:       "git che",
+			prefix:     "che",
+			values:     []string{"checkout", "cherry", "cherry-pick"},
+			wantLine:   "git che",
+			wantCursor: 7,
+		},
+		{
+			name:       "Common prefix extends word",
+			line:       "git ch",
+			prefix:     "ch",
+			values:     []string{"cherry", "cherry-pick"},
+			wantLine:   "git cherry",
+			wantCursor: 10,
+		},
+		{
+			name:       "Common prefix with different case",
+			line:       "ls mak",
+			prefix:     "mak",
+			values:     []string{"Makefile", "Makedir"},
+			wantLine:   "ls Make",
+			wantCursor: 7,
+		},
+		{
+			name:       "Non-ASCII prefix",
+			line:       "cat é",
+			prefix:     "é",
+			values:     []string{"étude.txt", "été.txt"},
+			wantLine:   "cat ét",
+			wantCursor: 6,
+		},
+		{
+			name:       "No common prefix",
+			line:       "ls ma",
+			prefix:     "ma",
+			values:     []string{"Makefile", "main.go"},
+			wantLine:   "ls ma",
+			wantCursor: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := core.Line([]rune(tt.line))
+			eng := &Engine{line: &line, cursor: core.NewCursor(&line), prefix: tt.prefix}
+			eng.cursor.Set(line.Len())
+			eng.groups = []*group{{rows: [][]Candidate{candidates(tt.values...)}}}
+
+			eng.insertCommonPrefix()
+
+			if string(line) != tt.wantLine {
+				t.Errorf("insertCommonPrefix() line = %q, want %q", string(line), tt.wantLine)
+			}
+
+			if eng.cursor.Pos() != tt.wantCursor {
+				t.Errorf("insertCommonPrefix() cursor = %d, want %d", eng.cursor.Pos(), tt.wantCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package completion
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/reeflective/readline/internal/color"
+	"github.com/reeflective/readline/internal/strutil"
+	"github.com/reeflective/readline/internal/term"
+)
+
+// Previewer is a function producing a preview for a completion candidate.
+// Its output is displayed in a pane below or beside the completions, and can
+// be a multiline string with color sequences. It runs in the background, so
+// that a slow previewer does not block the shell, and its output is cached
+// for each candidate until the completions are generated again.
+type Previewer func(comp Candidate) string
+
+const (
+	// previewWait is how long a new preview is waited for before displaying
+	// a placeholder: the interface is refreshed once the preview is done.
+	previewWait = 50 * time.Millisecond
+
+	// previewMinWidth is the minimum terminal width for displaying the preview
+	// beside the completions: below it, the preview is displayed below them.
+	previewMinWidth = 60
+
+	// previewSeparator separates the completions from the preview beside them.
+	previewSeparator = " │ "
+)
+
+// preview is the output of a previewer for a candidate, produced in the
+// background: the output can be read once the done channel is closed.
+type preview struct {
+	output string
+	done   chan struct{}
+}
+
+// SetPreviewRefresh sets the function used to refresh the interface when
+// the preview of a candidate is produced in the background. The function
+// is only called while the shell is waiting for input keys.
+func (e *Engine) SetPreviewRefresh(refresh func()) {
+	e.previewRefresh = refresh
+}
+
+// ScrollPreview scrolls the preview pane of the selected candidate by a
+// number of pages: negative values scroll up, positive values scroll down.
+func (e *Engine) ScrollPreview(pages int) {
+	if e.previewer == nil || len(e.previewLines) == 0 {
+		return
+	}
+
+	step := max(1, e.previewHeight-1)
+	e.previewOffset += pages * step
+
+	maxOffset := max(0, len(e.previewLines)-e.previewHeight)
+	e.previewOffset = max(0, min(e.previewOffset, maxOffset))
+}
+
+// updatePreview computes the preview lines of the selected candidate if
+// the selection has changed since the last time they were computed, or
+// if its preview was not done yet, and resets the preview scrolling offset
+// when the selection changes.
+func (e *Engine) updatePreview() {
+	if e.previewer == nil || len(e.selected.Value) == 0 {
+		e.resetPreview()
+		return
+	}
+
+	key := e.selected.Tag + "\x00" + e.selected.Value
+	if key == e.previewKey && e.previewDone {
+		return
+	}
+
+	if key != e.previewKey {
+		e.previewOffset = 0
+	}
+
+	output, done := e.previewOutput(key)
+
+	e.previewKey = key
+	e.previewDone = done
+	e.previewLines = nil
+
+	if !done {
+		e.previewLines = []string{color.Dim + "loading preview..." + color.Reset}
+		return
+	}
+
+	output = strings.TrimRight(output, "\r\n")
+	if output == "" {
+		return
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		e.previewLines = append(e.previewLines, strutil.FormatTabs(strings.TrimSuffix(line, "\r")))
+	}
+}
+
+// previewOutput returns the preview of the selected candidate, and whether
+// it is done. New previews are produced in the background and waited for a
+// little, so that fast previewers don't need a placeholder to be displayed.
+func (e *Engine) previewOutput(key string) (output string, done bool) {
+	result, found := e.previews[key]
+
+	if !found {
+		result = &preview{done: make(chan struct{})}
+
+		if e.previews == nil {
+			e.previews = make(map[string]*preview)
+		}
+
+		e.previews[key] = result
+
+		go e.runPreviewer(result, e.previewer, e.selected)
+
+		select {
+		case <-result.done:
+			return result.output, true
+		case <-time.After(previewWait):
+			return "", false
+		}
+	}
+
+	select {
+	case <-result.done:
+		return result.output, true
+	default:
+		return "", false
+	}
+}
+
+// runPreviewer produces the preview of a candidate, and refreshes the
+// interface if the shell is waiting for keys. Otherwise, the preview is
+// displayed the next time the interface is refreshed.
+func (e *Engine) runPreviewer(result *preview, previewer Previewer, comp Candidate) {
+	result.output = previewer(comp)
+	close(result.done)
+
+	if e.previewRefresh != nil && e.keys != nil {
+		e.keys.WhileWaiting(e.previewRefresh)
+	}
+}
+
+// renderPreview returns the preview pane of the currently selected
+// candidate, to be displayed below the completions, using at most
+// maxRows (including its header), and the number of rows it spans.
+func (e *Engine) renderPreview(maxRows int) (preview string, rows int) {
+	e.updatePreview()
+
+	// The preview should never take more than half the available space.
+	pane := e.previewPane(maxRows/2-1, term.GetWidth()-1)
+
+	var builder strings.Builder
+
+	for _, row := range pane {
+		builder.WriteString(term.NewlineReturn + row + term.ClearLineAfter)
+	}
+
+	return builder.String(), len(pane)
+}
+
+// renderPreviewBeside returns the cropped completions with the preview pane
+// of the currently selected candidate on their right, using at most maxRows,
+// and the number of rows used below the first one.
+func (e *Engine) renderPreviewBeside(completions string, maxRows int) (string, int) {
+	e.updatePreview()
+
+	menuWidth := e.menuWidth()
+	pane := e.previewPane(maxRows-1, e.previewWidth())
+
+	// Completion rows wider than the menu (like long messages)
+	// are wrapped, as the terminal would do without the preview.
+	var rows []string
+
+	for _, line := range strings.Split(completions, term.NewlineReturn) {
+		lineWidth := strutil.RealLength(line)
+		if lineWidth <= menuWidth {
+			rows = append(rows, line)
+			continue
+		}
+
+		for start := 0; start < lineWidth; start += menuWidth {
+			rows = append(rows, strutil.SliceColumns(line, start, start+menuWidth))
+		}
+	}
+
+	for len(rows) < len(pane) {
+		rows = append(rows, "")
+	}
+
+	var builder strings.Builder
+
+	for i, row := range rows {
+		if i > 0 {
+			builder.WriteString(term.NewlineReturn)
+		}
+
+		builder.WriteString(row)
+
+		if i < len(pane) {
+			pad := strings.Repeat(" ", max(0, menuWidth-strutil.RealLength(row)))
+			builder.WriteString(color.Reset + pad + color.Dim + previewSeparator + color.Reset)
+			builder.WriteString(pane[i] + term.ClearLineAfter)
+		}
+	}
+
+	return builder.String(), len(rows) - 1
+}
+
+// previewPane returns the rows of the preview pane: a header, followed by
+// at most height preview lines (fewer if configured so) truncated to width
+// columns. No rows are returned if there is no room for a preview line.
+func (e *Engine) previewPane(height, width int) []string {
+	if len(e.previewLines) == 0 {
+		e.previewHeight = 0
+		return nil
+	}
+
+	maxLines := e.config.GetInt("completion-preview-lines")
+	if maxLines <= 0 {
+		maxLines = len(e.previewLines)
+	}
+
+	e.previewHeight = min(len(e.previewLines), maxLines, height)
+	if e.previewHeight <= 0 {
+		e.previewHeight = 0
+		return nil
+	}
+
+	e.previewOffset = min(e.previewOffset, len(e.previewLines)-e.previewHeight)
+	lines := e.previewLines[e.previewOffset : e.previewOffset+e.previewHeight]
+
+	header := fmt.Sprintf("preview %d-%d/%d", e.previewOffset+1, e.previewOffset+len(lines), len(e.previewLines))
+	pane := []string{color.Dim + color.FgYellow + "── " + header + " ──" + color.Reset}
+
+	for _, line := range lines {
+		if strutil.RealLength(line) > width {
+			line = strutil.SliceColumns(line, 0, width)
+		}
+
+		pane = append(pane, line+color.Reset)
+	}
+
+	return pane
+}
+
+// previewWidth returns the width of the preview pane when it is displayed
+// beside the completions, or 0 when it is displayed below them.
+func (e *Engine) previewWidth() int {
+	if e.previewer == nil || e.config.GetString("completion-preview-position") != "right" {
+		return 0
+	}
+
+	width := term.GetWidth()
+	if width < previewMinWidth {
+		return 0
+	}
+
+	return (width - strutil.RealLength(previewSeparator)) / 2
+}
+
+// menuWidth returns the number of terminal columns available to the
+// completions: all of them, unless the preview is displayed beside.
+func (e *Engine) menuWidth() int {
+	width := term.GetWidth()
+
+	if preview := e.previewWidth(); preview > 0 {
+		width -= preview + strutil.RealLength(previewSeparator)
+	}
+
+	return width
+}
+
+func (e *Engine) resetPreview() {
+	e.previewKey = ""
+	e.previewDone = false
+	e.previewLines = nil
+	e.previewOffset = 0
+	e.previewHeight = 0
+}
@@ -0,0 +1,209 @@
+package completion
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/reeflective/readline/inputrc"
+	"github.com/reeflective/readline/internal/color"
+	"github.com/reeflective/readline/internal/strutil"
+	"github.com/reeflective/readline/internal/term"
+)
+
+func TestEngineRenderPreview(t *testing.T) {
+	lines := make([]string, 0, 20)
+	for i := range 20 {
+		lines = append(lines, "line "+strconv.Itoa(i))
+	}
+
+	config := inputrc.NewDefaultConfig()
+	config.Set("completion-preview-lines", 5)
+
+	eng := &Engine{config: config}
+	eng.previewer = func(comp Candidate) string {
+		return strings.Join(lines, "\n")
+	}
+
+	// No preview when no candidate is selected.
+	if preview, rows := eng.renderPreview(20); preview != "" || rows != 0 {
+		t.Fatalf("renderPreview() without selection = %q (%d rows), want none", preview, rows)
+	}
+
+	eng.selected = Candidate{Value: "file"}
+
+	// The configured number of lines, plus one header row.
+	preview, rows := eng.renderPreview(20)
+	if rows != 6 {
+		t.Errorf("renderPreview() rows = %d, want %d", rows, 6)
+	}
+
+	if !strings.Contains(preview, "line 0") || strings.Contains(preview, "line 5") {
+		t.Errorf("renderPreview() = %q, want lines 0 to 4", preview)
+	}
+
+	// Never use more than half of the available rows.
+	if _, rows = eng.renderPreview(6); rows != 3 {
+		t.Errorf("renderPreview() with few rows = %d, want %d", rows, 3)
+	}
+
+	// Scroll down by one page, and past the end.
+	eng.renderPreview(20)
+	eng.ScrollPreview(1)
+
+	if preview, _ = eng.renderPreview(20); !strings.Contains(preview, "line 4") || strings.Contains(preview, "line 3") {
+		t.Errorf("renderPreview() after scroll = %q, want lines 4 to 8", preview)
+	}
+
+	eng.ScrollPreview(10)
+
+	if preview, _ = eng.renderPreview(20); !strings.Contains(preview, "line 19") {
+		t.Errorf("renderPreview() after scroll to end = %q, want last line", preview)
+	}
+
+	// A new selection resets the scrolling.
+	eng.selected = Candidate{Value: "other"}
+
+	if preview, _ = eng.renderPreview(20); !strings.Contains(preview, "line 0") {
+		t.Errorf("renderPreview() after selection change = %q, want first line", preview)
+	}
+}
+
+func TestEnginePreviewPaneWidth(t *testing.T) {
+	width := term.GetWidth() - 1
+
+	tests := []struct {
+		name  string
+		line  string
+		width int
+	}{
+		{name: "Short line", line: "été", width: 3},
+		{name: "Multi-byte runes", line: strings.Repeat("é", width+10), width: width},
+		{name: "Wide runes", line: strings.Repeat("漢", width), width: width},
+		{name: "Colored line", line: "\x1b[31m" + strings.Repeat("é", width+10) + "\x1b[0m", width: width},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eng := &Engine{config: inputrc.NewDefaultConfig(), selected: Candidate{Value: "file"}}
+			eng.previewer = func(comp Candidate) string { return tt.line }
+
+			eng.updatePreview()
+
+			pane := eng.previewPane(1, width)
+			if len(pane) != 2 {
+				t.Fatalf("previewPane() = %q, want a header and one line", pane)
+			}
+
+			line := pane[1]
+			if got := strutil.RealLength(line); got != tt.width || !utf8.ValidString(line) {
+				t.Errorf("previewPane() line = %q (%d columns), want %d columns", line, got, tt.width)
+			}
+		})
+	}
+}
+
+func TestEngineUpdatePreviewBackground(t *testing.T) {
+	var calls atomic.Int32
+
+	release := make(chan struct{})
+	refreshed := make(chan struct{}, 1)
+
+	eng := &Engine{config: inputrc.NewDefaultConfig(), selected: Candidate{Value: "file"}}
+	eng.previewer = func(comp Candidate) string {
+		calls.Add(1)
+		<-release
+
+		return "slow preview"
+	}
+
+	// A slow previewer does not block: a placeholder is displayed meanwhile.
+	start := time.Now()
+	eng.updatePreview()
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("updatePreview() took %s, want it not to wait for the previewer", elapsed)
+	}
+
+	if len(eng.previewLines) != 1 || !strings.Contains(eng.previewLines[0], "loading") {
+		t.Errorf("updatePreview() lines = %q, want a placeholder", eng.previewLines)
+	}
+
+	// Without keys, the interface is not refreshed, but the
+	// preview is displayed the next time the pane is rendered.
+	eng.SetPreviewRefresh(func() { refreshed <- struct{}{} })
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for len(eng.previewLines) != 1 || eng.previewLines[0] != "slow preview" {
+		if time.Now().After(deadline) {
+			t.Fatalf("updatePreview() lines = %q, want the preview once done", eng.previewLines)
+		}
+
+		time.Sleep(time.Millisecond)
+		eng.updatePreview()
+	}
+
+	// Previews are cached for each candidate.
+	eng.selected = Candidate{Value: "other"}
+	eng.updatePreview()
+	eng.selected = Candidate{Value: "file"}
+	eng.updatePreview()
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("previewer called %d times, want once for each candidate", got)
+	}
+
+	if len(refreshed) != 0 {
+		t.Errorf("previewer refreshed the interface without keys")
+	}
+}
+
+func TestEngineRenderPreviewBeside(t *testing.T) {
+	width := term.GetWidth()
+	if width < previewMinWidth {
+		t.Skip("terminal too narrow to display the preview beside the completions")
+	}
+
+	config := inputrc.NewDefaultConfig()
+	config.Set("completion-preview-position", "right")
+
+	eng := &Engine{config: config, selected: Candidate{Value: "file"}}
+	eng.previewer = func(comp Candidate) string { return "first\nsecond\nthird" }
+
+	menuWidth := eng.menuWidth()
+	if got := menuWidth + strutil.RealLength(previewSeparator) + eng.previewWidth(); got != width {
+		t.Errorf("menu and preview widths = %d columns, want %d", got, width)
+	}
+
+	menu := "value" + term.NewlineReturn + strings.Repeat("m", menuWidth+2)
+
+	rendered, usedY := eng.renderPreviewBeside(menu, 20)
+	rows := strings.Split(rendered, term.NewlineReturn)
+
+	// The long menu row is wrapped, and the preview needs a fourth row.
+	if len(rows) != 4 || usedY != 3 {
+		t.Fatalf("renderPreviewBeside() = %q (usedY %d), want 4 rows", rows, usedY)
+	}
+
+	for i, want := range []string{"preview 1-3/3", "first", "second", "third"} {
+		row := color.Strip(rows[i])
+
+		separator := strings.Index(row, previewSeparator)
+		if separator < 0 || strutil.RealLength(row[:separator]) != menuWidth {
+			t.Fatalf("renderPreviewBeside() row %d = %q, want the preview at column %d", i, row, menuWidth)
+		}
+
+		if !strings.Contains(row[separator:], want) {
+			t.Errorf("renderPreviewBeside() row %d = %q, want %q on the right", i, row, want)
+		}
+	}
+
+	// Clicks on the preview don't select candidates.
+	if _, _, _, found := eng.candidateAt(0, menuWidth+1); found {
+		t.Errorf("candidateAt() on the preview found a candidate")
+	}
+}
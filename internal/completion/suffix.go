@@ -49,6 +49,21 @@ func (sm SuffixMatcher) Matches(s string) bool {
 	return false
 }
 
+// trim removes the last character of a value if it is explicitly
+// one of the suffixes: the wildcard suffix never trims anything.
+func (sm SuffixMatcher) trim(value string) string {
+	if value == "" {
+		return value
+	}
+
+	runes := []rune(value)
+	if strings.ContainsRune(sm.string, runes[len(runes)-1]) {
+		return string(runes[:len(runes)-1])
+	}
+
+	return value
+}
+
 type byRune []rune
 
 func (r byRune) Len() int           { return len(r) }
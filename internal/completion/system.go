@@ -0,0 +1,194 @@
+package completion
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Styles of the system completers candidates.
+const (
+	envStyle     = "36"
+	userStyle    = "33"
+	groupStyle   = "33"
+	hostStyle    = "35"
+	processStyle = "32"
+)
+
+// EnvVariables returns the variables of an environment (as returned by os.Environ),
+// prefixed with a dollar sign, and described by their values.
+func EnvVariables(environ []string) RawValues {
+	values := make(RawValues, 0, len(environ))
+
+	for _, variable := range environ {
+		name, value, found := strings.Cut(variable, "=")
+		if !found || name == "" {
+			continue
+		}
+
+		values = append(values, Candidate{
+			Value:       "$" + name,
+			Display:     "$" + name,
+			Description: value,
+			Style:       envStyle,
+			Tag:         "environment variables",
+		})
+	}
+
+	return values
+}
+
+// Users returns the users of a passwd file, described by their full name, or home directory.
+func Users(passwd string) RawValues {
+	var values RawValues
+
+	readFields(passwd, ":", func(fields []string) {
+		if len(fields) < 6 {
+			return
+		}
+
+		desc, _, _ := strings.Cut(fields[4], ",")
+		if desc == "" {
+			desc = fields[5]
+		}
+
+		values = append(values, Candidate{Value: fields[0], Description: desc, Style: userStyle, Tag: "users"})
+	})
+
+	return values
+}
+
+// Groups returns the groups of a group file, described by their ID.
+func Groups(group string) RawValues {
+	var values RawValues
+
+	readFields(group, ":", func(fields []string) {
+		if len(fields) < 3 {
+			return
+		}
+
+		values = append(values, Candidate{Value: fields[0], Description: "gid " + fields[2], Style: groupStyle, Tag: "groups"})
+	})
+
+	return values
+}
+
+// Hosts returns the host names found in a hosts file (described by their addresses),
+// in an SSH known_hosts file, and in an SSH config file, ignoring hashed hosts and
+// patterns. Hosts found in several files are only returned once.
+func Hosts(hosts, knownHosts, sshConfig string) RawValues {
+	var values RawValues
+
+	seen := make(map[string]bool)
+	add := func(host, desc string) {
+		if host == "" || seen[host] || strings.ContainsAny(host, "*?!") {
+			return
+		}
+
+		seen[host] = true
+		values = append(values, Candidate{Value: host, Description: desc, Style: hostStyle, Tag: "hosts"})
+	}
+
+	readFields(hosts, "", func(fields []string) {
+		for _, host := range fields[1:] {
+			add(host, fields[0])
+		}
+	})
+
+	readFields(knownHosts, "", func(fields []string) {
+		if strings.HasPrefix(fields[0], "@") && len(fields) > 1 {
+			fields = fields[1:]
+		}
+
+		for _, host := range strings.Split(fields[0], ",") {
+			if strings.HasPrefix(host, "|") {
+				continue
+			}
+
+			// Hosts on non-standard ports are written [host]:port.
+			if strings.HasPrefix(host, "[") {
+				host, _, _ = strings.Cut(host[1:], "]")
+			}
+
+			add(host, "known host")
+		}
+	})
+
+	readFields(sshConfig, "", func(fields []string) {
+		if !strings.EqualFold(fields[0], "Host") {
+			return
+		}
+
+		for _, host := range fields[1:] {
+			add(host, "ssh config")
+		}
+	})
+
+	return values
+}
+
+// Processes returns the IDs of the processes found in a proc
+// filesystem, in numerical order, described by their command name.
+func Processes(proc string) RawValues {
+	entries, err := os.ReadDir(proc)
+	if err != nil {
+		return nil
+	}
+
+	var pids []int
+
+	for _, entry := range entries {
+		if pid, err := strconv.Atoi(entry.Name()); err == nil && entry.IsDir() {
+			pids = append(pids, pid)
+		}
+	}
+
+	sort.Ints(pids)
+
+	values := make(RawValues, 0, len(pids))
+
+	for _, pid := range pids {
+		comm, err := os.ReadFile(filepath.Join(proc, strconv.Itoa(pid), "comm"))
+		if err != nil {
+			continue
+		}
+
+		values = append(values, Candidate{
+			Value:       strconv.Itoa(pid),
+			Description: strings.TrimSpace(string(comm)),
+			Style:       processStyle,
+			Tag:         "processes",
+		})
+	}
+
+	return values
+}
+
+// readFields calls a function on all non-empty, non-comment lines of a file, split
+// with a separator, or around whitespace if the separator is empty. Missing or
+// unreadable files are ignored.
+func readFields(path, sep string, fields func([]string)) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if sep == "" {
+			fields(strings.Fields(line))
+		} else {
+			fields(strings.Split(line, sep))
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package completion
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSystemCompleters(t *testing.T) {
+	fixture := func(name string) string {
+		return filepath.Join("testdata", "system", name)
+	}
+
+	tests := []struct {
+		name      string
+		values    RawValues
+		wantTag   string
+		want      []string
+		wantDescs []string
+	}{
+		{
+			name:      "Environment variables",
+			values:    EnvVariables([]string{"HOME=/home/alice", "EMPTY=", "=invalid", "PATH=/bin:/usr/bin"}),
+			wantTag:   "environment variables",
+			want:      []string{"$HOME", "$EMPTY", "$PATH"},
+			wantDescs: []string{"/home/alice", "", "/bin:/usr/bin"},
+		},
+		{
+			name:      "Users",
+			values:    Users(fixture("passwd")),
+			wantTag:   "users",
+			want:      []string{"root", "alice", "svc"},
+			wantDescs: []string{"root", "Alice Smith", "/var/lib/svc"},
+		},
+		{
+			name:      "Groups",
+			values:    Groups(fixture("group")),
+			wantTag:   "groups",
+			want:      []string{"root", "wheel", "docker"},
+			wantDescs: []string{"gid 0", "gid 10", "gid 998"},
+		},
+		{
+			name:    "Hosts",
+			values:  Hosts(fixture("hosts"), fixture("known_hosts"), fixture("ssh_config")),
+			wantTag: "hosts",
+			want: []string{
+				"localhost", "ip6-localhost", "build.internal", "build",
+				"github.com", "140.82.121.4", "git.example.com", "bastion", "jump",
+			},
+			wantDescs: []string{
+				"127.0.0.1", "::1", "10.0.0.5", "10.0.0.5",
+				"known host", "known host", "known host", "ssh config", "ssh config",
+			},
+		},
+		{
+			name:      "Processes",
+			values:    Processes(fixture("proc")),
+			wantTag:   "processes",
+			want:      []string{"1", "42"},
+			wantDescs: []string{"systemd", "sleep"},
+		},
+		{
+			name:   "Missing file",
+			values: Users(fixture("missing")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.values) != len(tt.want) {
+				t.Fatalf("values = %v, want %v", tt.values, tt.want)
+			}
+
+			for i, comp := range tt.values {
+				if comp.Value != tt.want[i] || comp.Description != tt.wantDescs[i] {
+					t.Errorf("value %d = %q (%q), want %q (%q)",
+						i, comp.Value, comp.Description, tt.want[i], tt.wantDescs[i])
+				}
+
+				if comp.Tag != tt.wantTag || comp.Style == "" {
+					t.Errorf("value %d tag = %q (style %q), want %q with a style", i, comp.Tag, comp.Style, tt.wantTag)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package completion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/reeflective/readline/internal/term"
+)
+
+// treeNode is a node of a group of candidates displayed as a tree: either a path
+// prefix shared by several candidates, which can be expanded or collapsed, or a
+// candidate itself.
+type treeNode struct {
+	path     string      // The full path of the node, ending with the separator if it is not a candidate.
+	name     string      // The last path segment.
+	depth    int         // The number of parent nodes.
+	parent   *treeNode   // The parent node, nil for the tree roots.
+	leaf     *Candidate  // The candidate, if the node is not a path prefix.
+	children []*treeNode // Child nodes, in the candidates order.
+}
+
+// initCompletionsTree arranges completions as a tree of their path segments, listing
+// the nodes that are visible, that is, the roots and all children of expanded nodes.
+// Nodes leading to the current completion prefix, or without siblings, are expanded.
+func (g *group) initCompletionsTree(comps RawValues, prefix string) {
+	g.list = true
+	g.treeExpanded = make(map[string]bool)
+
+	for i := range comps {
+		comp := comps[i]
+		segments := strings.Split(comp.Value, g.treeSep)
+
+		siblings := &g.tree
+		var parent *treeNode
+
+		for depth, segment := range segments[:len(segments)-1] {
+			path := strings.Join(segments[:depth+1], g.treeSep) + g.treeSep
+
+			node := findTreeNode(*siblings, path)
+			if node == nil {
+				node = &treeNode{path: path, name: segment, depth: depth, parent: parent}
+				*siblings = append(*siblings, node)
+			}
+
+			parent = node
+			siblings = &node.children
+		}
+
+		leaf := &treeNode{
+			path:   comp.Value,
+			name:   segments[len(segments)-1],
+			depth:  len(segments) - 1,
+			parent: parent,
+			leaf:   &comp,
+		}
+
+		*siblings = append(*siblings, leaf)
+	}
+
+	g.expandTree(g.tree, prefix)
+	g.layoutTree()
+}
+
+// expandTree expands the nodes leading to the completion prefix, and those without siblings.
+func (g *group) expandTree(nodes []*treeNode, prefix string) {
+	for _, node := range nodes {
+		if node.leaf != nil {
+			continue
+		}
+
+		if len(nodes) == 1 || strings.HasPrefix(prefix, node.path) {
+			g.treeExpanded[node.path] = true
+		}
+
+		g.expandTree(node.children, prefix)
+	}
+}
+
+// layoutTree computes the rows of candidates from the visible nodes of
+// the tree, keeping the selection on the node that was selected, if any.
+func (g *group) layoutTree() {
+	var selected string
+	if g.posY >= 0 && g.posY < len(g.treeRows) {
+		selected = g.treeRows[g.posY].path
+	}
+
+	g.treeRows = g.visibleNodes(g.tree, nil)
+	g.rows = make([][]Candidate, 0, len(g.treeRows))
+
+	for i, node := range g.treeRows {
+		g.rows = append(g.rows, []Candidate{g.treeCandidate(node)})
+
+		if selected != "" && node.path == selected {
+			g.posY = i
+		}
+	}
+
+	for _, row := range g.rows {
+		g.prepareValues(row)
+	}
+
+	g.columnsWidth = []int{0}
+	g.calculateMaxColumnWidths(g.rows)
+}
+
+func (g *group) visibleNodes(nodes, visible []*treeNode) []*treeNode {
+	for _, node := range nodes {
+		visible = append(visible, node)
+
+		if g.treeExpanded[node.path] {
+			visible = g.visibleNodes(node.children, visible)
+		}
+	}
+
+	return visible
+}
+
+// treeCandidate returns the candidate of a node, displayed with its indentation, and
+// whether it is expanded if it is a path prefix. Its value is always the full path.
+func (g *group) treeCandidate(node *treeNode) Candidate {
+	indent := strings.Repeat("  ", node.depth)
+
+	if node.leaf != nil {
+		comp := *node.leaf
+		comp.Display = indent + "  " + node.name
+
+		return comp
+	}
+
+	marker := "▸ "
+	if g.treeExpanded[node.path] {
+		marker = "▾ "
+	}
+
+	return Candidate{
+		Value:       node.path,
+		Display:     indent + marker + node.name + g.treeSep,
+		Description: fmt.Sprintf("(%d)", len(node.children)),
+		Tag:         g.tag,
+	}
+}
+
+// toggleTree handles the left and right arrow keys in a tree group: left collapses
+// the selected node, or selects its parent, and right expands the selected node,
+// or selects its first child. It returns false if the keys are not tree keys.
+func (g *group) toggleTree(keys string) bool {
+	if g.tree == nil || (keys != term.ArrowLeft && keys != term.ArrowRight) {
+		return false
+	}
+
+	if g.posY < 0 || g.posY >= len(g.treeRows) {
+		g.firstCell()
+		return true
+	}
+
+	node := g.treeRows[g.posY]
+	expanded := g.treeExpanded[node.path]
+
+	switch {
+	case keys == term.ArrowRight && node.leaf == nil && !expanded:
+		g.treeExpanded[node.path] = true
+	case keys == term.ArrowRight && node.leaf == nil:
+		g.posY++
+	case keys == term.ArrowLeft && node.leaf == nil && expanded:
+		g.treeExpanded[node.path] = false
+	case keys == term.ArrowLeft && node.parent != nil:
+		g.posY = g.treeRowIndex(node.parent)
+	}
+
+	g.posX = 0
+	g.layoutTree()
+
+	return true
+}
+
+func (g *group) treeRowIndex(node *treeNode) int {
+	for i, visible := range g.treeRows {
+		if visible == node {
+			return i
+		}
+	}
+
+	return 0
+}
+
+func findTreeNode(nodes []*treeNode, path string) *treeNode {
+	for _, node := range nodes {
+		if node.leaf == nil && node.path == path {
+			return node
+		}
+	}
+
+	return nil
+}
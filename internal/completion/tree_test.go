@@ -0,0 +1,89 @@
+package completion
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/reeflective/readline/internal/term"
+)
+
+func treeDisplays(grp *group) []string {
+	displays := make([]string, 0, len(grp.rows))
+	for _, row := range grp.rows {
+		displays = append(displays, strings.TrimSpace(row[0].Display))
+	}
+
+	return displays
+}
+
+func TestGroupTree(t *testing.T) {
+	grp := &group{treeSep: ".", posX: -1, posY: -1, termWidth: 80}
+	grp.initCompletionsTree(candidates("core.editor", "core.pager", "user.email", "user.name"), "")
+
+	want := []string{"▸ core.", "▸ user."}
+	if got := treeDisplays(grp); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("initCompletionsTree() rows = %q, want %q", got, want)
+	}
+
+	steps := []struct {
+		name     string
+		keys     string
+		wantRows []string
+		wantSel  string
+	}{
+		{
+			name:     "Expand first node",
+			keys:     term.ArrowRight,
+			wantRows: []string{"▾ core.", "editor", "pager", "▸ user."},
+			wantSel:  "core.",
+		},
+		{
+			name:     "Select first child",
+			keys:     term.ArrowRight,
+			wantRows: []string{"▾ core.", "editor", "pager", "▸ user."},
+			wantSel:  "core.editor",
+		},
+		{
+			name:     "Select parent",
+			keys:     term.ArrowLeft,
+			wantRows: []string{"▾ core.", "editor", "pager", "▸ user."},
+			wantSel:  "core.",
+		},
+		{
+			name:     "Collapse node",
+			keys:     term.ArrowLeft,
+			wantRows: []string{"▸ core.", "▸ user."},
+			wantSel:  "core.",
+		},
+	}
+
+	grp.firstCell()
+
+	for _, step := range steps {
+		if !grp.toggleTree(step.keys) {
+			t.Fatalf("%s: toggleTree() = false, want true", step.name)
+		}
+
+		if got := treeDisplays(grp); strings.Join(got, "|") != strings.Join(step.wantRows, "|") {
+			t.Errorf("%s: rows = %q, want %q", step.name, got, step.wantRows)
+		}
+
+		if got := grp.selected().Value; got != step.wantSel {
+			t.Errorf("%s: selected = %q, want %q", step.name, got, step.wantSel)
+		}
+	}
+
+	if grp.toggleTree(term.ArrowDown) {
+		t.Errorf("toggleTree(ArrowDown) = true, want false")
+	}
+}
+
+func TestGroupTreeExpandPrefix(t *testing.T) {
+	grp := &group{treeSep: "/", posX: -1, posY: -1, termWidth: 80}
+	grp.initCompletionsTree(candidates("docs/index.md", "src/cmd/main.go", "src/lib/lib.go"), "src/")
+
+	want := []string{"▸ docs/", "▾ src/", "▸ cmd/", "▸ lib/"}
+	if got := treeDisplays(grp); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("initCompletionsTree() rows = %q, want %q", got, want)
+	}
+}
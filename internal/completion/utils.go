@@ -26,9 +26,13 @@ var sanitizer = strings.NewReplacer(
 func (e *Engine) prepare(completions Values) {
 	e.prefix = ""
 	e.groups = make([]*group, 0)
+	e.previewer = completions.Preview
+	e.previews = nil
+	e.resetPreview()
 
 	e.setPrefix(completions)
 	e.setSuffix(completions)
+	e.rankCandidates(&completions)
 	e.generate(completions)
 }
 
@@ -148,7 +152,7 @@ func (e *Engine) groupNonDescribed(comps *Values, values RawValues) (vals, noDes
 
 		// Currently this is because errors are passed as completions.
 		if strings.HasPrefix(val.Value, prefix+"ERR") && val.Value == prefix+"_" {
-			comps.Messages.Add(color.FgRed + val.Display + val.Description)
+			comps.Messages.AddMessage(Message{Text: val.Display + val.Description, Level: MessageError})
 
 			continue
 		}
@@ -288,16 +292,21 @@ func (e *Engine) adjustCycleKeys(row, column int) (int, int) {
 	keyRunes := e.keys.Caller()
 	keys := string(keyRunes)
 
+	// Column-major grids are cycled from top to bottom,
+	// except when explicitly moving left or right.
+	vertical := !cur.horizontal && !cur.aliased && row != 0 &&
+		keys != term.ArrowLeft && keys != term.ArrowRight
+
 	if row > 0 {
 		if cur.aliased && keys != term.ArrowRight && keys != term.ArrowDown {
 			row, column = 0, row
-		} else if keys == term.ArrowDown {
+		} else if keys == term.ArrowDown || vertical {
 			row, column = 0, row
 		}
 	} else {
 		if cur.aliased && keys != term.ArrowLeft && keys != term.ArrowUp {
 			row, column = 0, 1*row
-		} else if keys == term.ArrowUp {
+		} else if keys == term.ArrowUp || vertical {
 			row, column = 0, 1*row
 		}
 	}
@@ -305,6 +314,18 @@ func (e *Engine) adjustCycleKeys(row, column int) (int, int) {
 	return row, column
 }
 
+// displayWidth returns the number of terminal columns usable by completions,
+// capped by the completion-display-width option when it has a valid value.
+func (e *Engine) displayWidth() int {
+	width := e.menuWidth()
+
+	if limit := e.config.GetInt("completion-display-width"); limit > 0 && limit < width {
+		width = limit
+	}
+
+	return width
+}
+
 // adjustSelectKeymap is only called when the selector function has been used.
 func (e *Engine) adjustSelectKeymap() {
 	if e.keymap.Local() != keymap.Isearch {
@@ -322,15 +343,13 @@ func (e *Engine) completionCount() (comps int, used int) {
 			comps += len(row)
 		}
 
-		// One line for the group name
-		if group.tag != "" {
-			used++
-		}
+		// One line for the group name, and its messages.
+		used += group.headerRows()
+
+		used += group.rowsHeight(len(group.rows))
 
 		if group.maxY > len(group.rows) {
-			used += group.maxY
-		} else {
-			used += len(group.rows)
+			used += group.maxY - len(group.rows)
 		}
 	}
 
@@ -391,6 +410,8 @@ func (e *Engine) resetValues(comps, cached bool) {
 	if comps {
 		e.usedY = 0
 		e.groups = make([]*group, 0)
+		e.marked = nil
+		e.ranking = nil
 	}
 
 	// Drop the completion generation function.
@@ -446,18 +467,18 @@ func (e *Engine) getAbsPos() int {
 			continue
 		}
 
-		if grp.tag != "" {
-			prev++
-		}
+		prev += grp.headerRows()
 
+		// The selected candidate is fully displayed
+		// when its description spans several rows.
 		if grp.isCurrent {
-			prev += grp.posY
+			prev += grp.rowsHeight(grp.posY) + grp.rowHeight(grp.posY) - 1
 			foundCurrent = true
 
 			break
 		}
 
-		prev += grp.maxY
+		prev += grp.rowsHeight(len(grp.rows)) + max(0, grp.maxY-len(grp.rows))
 	}
 
 	// If there was no current group, it means
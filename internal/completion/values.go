@@ -1,7 +1,10 @@
 package completion
 
 import (
+	"slices"
 	"strings"
+
+	"github.com/reeflective/readline/internal/strutil"
 )
 
 // RawValues is a list of completion candidates.
@@ -25,21 +28,86 @@ func (c RawValues) Filter(values ...string) RawValues {
 	return filtered
 }
 
-// Merge merges a set of values with the current ones,
-// include usage/message strings, meta settings, etc.
+// Raw returns the completion candidates.
+func (c Values) Raw() RawValues {
+	return c.values
+}
+
+// Merge merges a set of values with the current ones, include usage/message strings,
+// meta settings, etc. Values already present are replaced with the other ones, and
+// settings already present for a tag are kept. The other values are not modified.
 func (c *Values) Merge(other Values) {
 	if other.Usage != "" {
 		c.Usage = other.Usage
 	}
 
+	if c.Preview == nil {
+		c.Preview = other.Preview
+	}
+
 	c.NoSpace.Merge(other.NoSpace)
 	c.Messages.Merge(other.Messages)
 
-	for tag := range other.ListLong {
-		if _, found := c.ListLong[tag]; !found {
-			c.ListLong[tag] = true
+	values := make(RawValues, 0, len(c.values)+len(other.values))
+	indexes := make(map[string]int, cap(values))
+
+	for _, val := range append(slices.Clone(c.values), other.values...) {
+		if i, found := indexes[val.Value]; found {
+			values[i] = val
+			continue
 		}
+
+		indexes[val.Value] = len(values)
+		values = append(values, val)
 	}
+
+	c.values = values
+
+	c.ListLong = MergeTags(c.ListLong, other.ListLong)
+	c.Horizontal = MergeTags(c.Horizontal, other.Horizontal)
+	c.NoSort = MergeTags(c.NoSort, other.NoSort)
+	c.ListSep = MergeTags(c.ListSep, other.ListSep)
+	c.Pad = MergeTags(c.Pad, other.Pad)
+	c.Escapes = MergeTags(c.Escapes, other.Escapes)
+	c.ExpandDesc = MergeTags(c.ExpandDesc, other.ExpandDesc)
+	c.Tree = MergeTags(c.Tree, other.Tree)
+}
+
+// Quote returns a copy of the values, each of them quoted so as to be inserted in place
+// of the raw prefix of the current word, which unquoted form is unquoted. The quote is
+// the one still open at the cursor position, if any. When closeQuote is true, values
+// are ended with the open quote, unless they end with one of the no-space suffixes,
+// in which case more input is expected within the quotes.
+func (c Values) Quote(raw, unquoted string, quote rune, closeQuote bool) Values {
+	quoted := make(RawValues, 0, len(c.values))
+
+	for _, val := range c.values {
+		value := val.Value
+
+		if val.Display == "" {
+			val.Display = value
+		}
+
+		switch {
+		case strings.HasPrefix(value, unquoted):
+			val.Value = raw + strutil.EscapeWord(value[len(unquoted):], quote)
+		case quote != 0:
+			val.Value = string(quote) + strutil.EscapeWord(value, quote)
+		default:
+			val.Value = strutil.EscapeWord(value, quote)
+		}
+
+		if closeQuote && quote != 0 && value != "" && !c.NoSpace.Matches(value) {
+			val.Value += string(quote)
+		}
+
+		val.quoted = true
+		quoted = append(quoted, val)
+	}
+
+	c.values = quoted
+
+	return c
 }
 
 // EachTag iterates over each tag and runs a function for each group.
@@ -98,3 +166,18 @@ func (c RawValues) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
 func (c RawValues) Less(i, j int) bool {
 	return strings.ToLower(c[i].Value) < strings.ToLower(c[j].Value)
 }
+
+// MergeTags adds the per-tag settings not yet present in a map, which is created if needed.
+func MergeTags[T any](settings, other map[string]T) map[string]T {
+	if settings == nil && len(other) > 0 {
+		settings = make(map[string]T, len(other))
+	}
+
+	for tag, setting := range other {
+		if _, found := settings[tag]; !found {
+			settings[tag] = setting
+		}
+	}
+
+	return settings
+}
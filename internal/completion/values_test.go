@@ -0,0 +1,115 @@
+package completion
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValuesQuote(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     []string
+		noSpace    string
+		raw        string
+		unquoted   string
+		quote      rune
+		closeQuote bool
+		want       []string
+	}{
+		{
+			name:   "Unquoted word, escaped values",
+			values: []string{"file.go", "my file.go"},
+			want:   []string{"file.go", `my\ file.go`},
+		},
+		{
+			name:     "Escaped prefix is kept as typed",
+			values:   []string{"my file.go"},
+			raw:      `my\ f`,
+			unquoted: "my f",
+			want:     []string{`my\ file.go`},
+		},
+		{
+			name:       "Unclosed single quote",
+			values:     []string{"my file.go", "it's.go"},
+			raw:        "'",
+			quote:      '\'',
+			closeQuote: true,
+			want:       []string{"'my file.go'", `'it'\''s.go'`},
+		},
+		{
+			name:     "Quote closed later in the word",
+			values:   []string{"my file.go"},
+			raw:      `"my`,
+			unquoted: "my",
+			quote:    '"',
+			want:     []string{`"my file.go`},
+		},
+		{
+			name:       "Quote left open on no-space suffixes",
+			values:     []string{"my dir/", "my file.go"},
+			noSpace:    "/",
+			raw:        `"my`,
+			unquoted:   "my",
+			quote:      '"',
+			closeQuote: true,
+			want:       []string{`"my dir/`, `"my file.go"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := AddRaw(candidates(tt.values...))
+			values.NoSpace.Add([]rune(tt.noSpace)...)
+
+			quoted := values.Quote(tt.raw, tt.unquoted, tt.quote, tt.closeQuote)
+
+			for i, comp := range quoted.values {
+				if comp.Value != tt.want[i] {
+					t.Errorf("Quote() value = %v, want %v", comp.Value, tt.want[i])
+				}
+
+				if comp.Display != tt.values[i] {
+					t.Errorf("Quote() display = %v, want %v", comp.Display, tt.values[i])
+				}
+			}
+
+			// The original values must not be modified.
+			if values.values[0].Value != tt.values[0] {
+				t.Errorf("Quote() modified original value %v", values.values[0].Value)
+			}
+		})
+	}
+}
+
+func TestValuesMerge(t *testing.T) {
+	values := AddRaw(nil)
+
+	first := AddRaw(candidates("main", "dev"))
+	first.Usage = "branches"
+	first.Tree["files"] = "/"
+
+	second := AddRaw([]Candidate{{Value: "dev", Description: "development"}, {Value: "--force"}})
+	second.NoSort["*"] = true
+	second.Tree["files"] = "."
+	second.Escapes = map[string]bool{"*": true}
+
+	values.Merge(first)
+	values.Merge(second)
+
+	want := []string{"main", "dev", "--force"}
+	if got := gridValues([][]Candidate{values.values})[0]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge() values = %v, want %v", got, want)
+	}
+
+	if values.values[1].Description != "development" {
+		t.Errorf("Merge() did not replace a value already present")
+	}
+
+	if values.Usage != "branches" || !values.NoSort["*"] || !values.Escapes["*"] || values.Tree["files"] != "/" {
+		t.Errorf("Merge() settings = %q %v %v %v", values.Usage, values.NoSort, values.Escapes, values.Tree)
+	}
+
+	if len(first.values) != 2 || len(second.values) != 2 {
+		t.Errorf("Merge() modified the merged values")
+	}
+}
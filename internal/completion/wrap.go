@@ -0,0 +1,142 @@
+package completion
+
+import (
+	"strings"
+
+	"github.com/reeflective/readline/internal/color"
+	"github.com/rivo/uniseg"
+)
+
+// wrapsDescriptions returns true if descriptions may span several rows:
+// this is only possible when candidates are listed one per row.
+func (g *group) wrapsDescriptions() bool {
+	return g.list && !g.aliased && len(g.columnsWidth) == 1 &&
+		(g.descLines != 1 || g.expandSelected)
+}
+
+// descriptionLines returns the rows used by the description of a candidate,
+// or nil if the description fits on the candidate row or is not wrapped.
+func (g *group) descriptionLines(val Candidate, selected bool) []string {
+	if !g.wrapsDescriptions() || val.Description == "" {
+		return nil
+	}
+
+	maxLines := g.descLines
+
+	if g.expandSelected {
+		maxLines = 1
+		if selected {
+			maxLines = 0
+		}
+	}
+
+	if maxLines == 1 {
+		return nil
+	}
+
+	width := g.setMaximumSizes(0) - trailingDescLen
+	if width <= 0 {
+		return nil
+	}
+
+	if val.descLen <= width && !strings.ContainsAny(val.Description, "\r\n") {
+		return nil
+	}
+
+	return wrapText(color.Strip(val.Description), width, maxLines)
+}
+
+// rowHeight returns the number of terminal rows used by a row of candidates.
+func (g *group) rowHeight(row int) int {
+	if !g.wrapsDescriptions() || row >= len(g.rows) || len(g.rows[row]) == 0 {
+		return 1
+	}
+
+	selected := g.isCurrent && row == g.posY
+
+	return max(1, len(g.descriptionLines(g.rows[row][0], selected)))
+}
+
+// rowsHeight returns the number of terminal rows used by the first rows of candidates.
+func (g *group) rowsHeight(rows int) (height int) {
+	for row := range rows {
+		height += g.rowHeight(row)
+	}
+
+	return height
+}
+
+// wrapText wraps text on word boundaries so that no line is wider than width
+// columns, keeping the newlines it contains. If maxLines is positive and the
+// text needs more lines, the last one ends with an ellipsis.
+func wrapText(text string, width, maxLines int) []string {
+	var lines []string
+
+	text = strings.ReplaceAll(text, "\r", "")
+	text = strings.ReplaceAll(text, "\t", " ")
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		var line strings.Builder
+		var lineWidth int
+
+		for _, word := range strings.Fields(paragraph) {
+			wordWidth := uniseg.StringWidth(word)
+
+			if lineWidth > 0 && lineWidth+1+wordWidth > width {
+				lines = append(lines, line.String())
+				line.Reset()
+				lineWidth = 0
+			}
+
+			// Words longer than a line are split.
+			for wordWidth > width {
+				head, rest := cutWidth(word, width)
+				lines = append(lines, head)
+				word, wordWidth = rest, uniseg.StringWidth(rest)
+			}
+
+			if lineWidth > 0 {
+				line.WriteByte(' ')
+				lineWidth++
+			}
+
+			line.WriteString(word)
+			lineWidth += wordWidth
+		}
+
+		lines = append(lines, line.String())
+	}
+
+	if maxLines > 0 && len(lines) > maxLines {
+		lines = lines[:maxLines]
+
+		last := lines[maxLines-1]
+		if uniseg.StringWidth(last) > width-3 {
+			last, _ = cutWidth(last, max(0, width-3))
+		}
+
+		lines[maxLines-1] = last + "..."
+	}
+
+	return lines
+}
+
+// cutWidth returns the longest beginning of a string fitting in width columns
+// (but at least its first character), and the rest of the string.
+func cutWidth(str string, width int) (head, rest string) {
+	var used int
+
+	rest = str
+
+	for rest != "" {
+		_, remaining, clusterWidth, _ := uniseg.FirstGraphemeClusterInString(rest, -1)
+		if used > 0 && used+clusterWidth > width || width == 0 {
+			break
+		}
+
+		used += clusterWidth
+		rest = remaining
+	}
+
+	return str[:len(str)-len(rest)], rest
+}
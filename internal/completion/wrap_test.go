@@ -0,0 +1,167 @@
+package completion
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		width    int
+		maxLines int
+		want     []string
+	}{
+		{
+			name:  "Fits on one line",
+			text:  "short help",
+			width: 20,
+			want:  []string{"short help"},
+		},
+		{
+			name:  "Wrapped on words",
+			text:  "print the version of the program and exit",
+			width: 16,
+			want:  []string{"print the", "version of the", "program and exit"},
+		},
+		{
+			name:  "Newlines kept",
+			text:  "first line\nsecond line",
+			width: 40,
+			want:  []string{"first line", "second line"},
+		},
+		{
+			name:  "Long words split",
+			text:  "abcdefghij",
+			width: 4,
+			want:  []string{"abcd", "efgh", "ij"},
+		},
+		{
+			name:     "Lines limited with ellipsis",
+			text:     "print the version of the program and exit",
+			width:    16,
+			maxLines: 2,
+			want:     []string{"print the", "version of th..."},
+		},
+		{
+			name:  "Wide characters wrapped on display width",
+			text:  "列出 目录 内容",
+			width: 9,
+			want:  []string{"列出 目录", "内容"},
+		},
+		{
+			name:  "Wide words split on display width",
+			text:  "日本語のテキスト",
+			width: 5,
+			want:  []string{"日本", "語の", "テキ", "スト"},
+		},
+		{
+			name:  "Emoji split on grapheme clusters",
+			text:  "🚀🚀🚀",
+			width: 5,
+			want:  []string{"🚀🚀", "🚀"},
+		},
+		{
+			name:     "Wide characters limited with ellipsis",
+			text:     "列出 目录 内容",
+			width:    8,
+			maxLines: 1,
+			want:     []string{"列出..."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapText(tt.text, tt.width, tt.maxLines)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("wrapText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupRowsHeight(t *testing.T) {
+	long := strings.Repeat("word ", 20)
+
+	tests := []struct {
+		name           string
+		descLines      int
+		expandSelected bool
+		posY           int
+		want           int
+	}{
+		{
+			name:      "Descriptions on one row",
+			descLines: 1,
+			want:      3,
+		},
+		{
+			name:      "Wrapped descriptions, limited",
+			descLines: 2,
+			want:      5,
+		},
+		{
+			name:           "Only selected description expanded",
+			descLines:      1,
+			expandSelected: true,
+			posY:           1,
+			want:           2 + 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comps := candidates("--all", "--verbose", "--version")
+			comps[0].Description = long
+			comps[1].Description = long
+			comps[2].Description = "short"
+
+			for i := range comps {
+				comps[i].descLen = len(comps[i].Description)
+				comps[i].displayLen = len(comps[i].Display)
+			}
+
+			grp := &group{
+				list:              true,
+				isCurrent:         true,
+				posY:              tt.posY,
+				termWidth:         40,
+				listSeparator:     "--",
+				descLines:         tt.descLines,
+				expandSelected:    tt.expandSelected,
+				rows:              [][]Candidate{{comps[0]}, {comps[1]}, {comps[2]}},
+				columnsWidth:      []int{10},
+				descriptionsWidth: []int{len(long) + 1},
+			}
+
+			if got := grp.rowsHeight(len(grp.rows)); got != tt.want {
+				t.Errorf("rowsHeight() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageRows(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  int
+	}{
+		{name: "Empty message", text: "", width: 10, want: 1},
+		{name: "Fits on one row", text: "no matches", width: 10, want: 1},
+		{name: "Wrapped message", text: "no matches found", width: 10, want: 2},
+		{name: "Wide characters", text: "没有找到匹配项", width: 10, want: 2},
+		{name: "Colors ignored", text: "\x1b[31mno matches\x1b[0m", width: 10, want: 1},
+		{name: "Newlines", text: "no matches\nfound", width: 10, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := messageRows(tt.text, tt.width); got != tt.want {
+				t.Errorf("messageRows(%q, %d) = %d, want %d", tt.text, tt.width, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/reeflective/readline/internal/term"
+)
+
+var (
+	// DCS > | name version ST, the answer to XTVERSION.
+	rxVersion = regexp.MustCompile(`\x1bP>\|([^\x1b]*)\x1b\\`)
+
+	// CSI ? 2026 ; mode $ y, the answer to DECRQM for synchronized output.
+	rxSyncOutput = regexp.MustCompile(`\x1b\[\?2026;([0-9])\$y`)
+)
+
+// DetectCapabilities refines the terminal capabilities detected from the environment
+// with the terminal answers to its device attributes, XTVERSION and synchronized output
+// queries. The terminal is only queried once, and nothing changes if it does not answer.
+func (k *Keys) DetectCapabilities() {
+	if k.detected {
+		return
+	}
+
+	k.detected = true
+
+	version, attributes := k.QueryTerminal(term.VersionQuery, rxVersion)
+	if attributes == nil {
+		return
+	}
+
+	syncMode := -1
+
+	if mode, _ := k.QueryTerminal(term.SyncOutputQuery, rxSyncOutput); mode != nil {
+		syncMode, _ = strconv.Atoi(string(rxSyncOutput.FindSubmatch(mode)[1]))
+	}
+
+	if version != nil {
+		version = rxVersion.FindSubmatch(version)[1]
+	}
+
+	term.Caps.Update(deviceAttributes(attributes), string(version), syncMode)
+}
+
+// deviceAttributes returns the parameters of a primary device attributes answer.
+func deviceAttributes(answer []byte) (attributes []int) {
+	params := strings.TrimSuffix(strings.TrimPrefix(string(answer), "\x1b[?"), "c")
+
+	for _, param := range strings.Split(params, ";") {
+		if attr, err := strconv.Atoi(param); err == nil {
+			attributes = append(attributes, attr)
+		}
+	}
+
+	return attributes
+}
@@ -358,9 +358,14 @@ func (c *Cursor) CheckCommand() {
 // CoordinatesCursor returns the number of real terminal lines above the cursor position
 // (y value), and the number of columns since the beginning of the current line (x value).
 // @indent -    Used to align all lines (except the first) together on a single column.
-func CoordinatesCursor(cur *Cursor, indent int) (x, y int) {
+// @scroll -    If not nil, the line is displayed on a single row, from the scroll offset.
+func CoordinatesCursor(cur *Cursor, indent int, scroll *Scroll) (x, y int) {
 	cur.CheckAppend()
 
+	if scroll.Scrolls(cur.line) {
+		return scroll.coordinates(cur.line, cur.pos, indent), 0
+	}
+
 	newlines := cur.line.newlines()
 	bpos := 0
 	usedY := 0
@@ -388,6 +393,38 @@ func CoordinatesCursor(cur *Cursor, indent int) (x, y int) {
 	return
 }
 
+// PositionAt returns the line position displayed at the given coordinates, which are
+// computed like those returned by CoordinatesCursor: x is the terminal column and y the
+// number of terminal lines below the first one. Coordinates past the end of a line row,
+// or past the end of the line, return the last position on this row (or of the line).
+func PositionAt(cur *Cursor, indent int, scroll *Scroll, x, y int) int {
+	probe := NewCursor(cur.line)
+	found, onRow := 0, false
+
+	for pos := 0; pos <= cur.line.Len(); pos++ {
+		probe.pos = pos
+
+		posX, posY := CoordinatesCursor(probe, indent, scroll)
+		if posY > y {
+			break
+		}
+
+		// Clicking before the first column of a row
+		// selects the first position on this row.
+		if posY == y && posX > x {
+			if !onRow {
+				found = pos
+			}
+
+			break
+		}
+
+		found, onRow = pos, posY == y
+	}
+
+	return found
+}
+
 func (c *Cursor) moveLineDown() {
 	var cpos, begin int
 	begin = -1
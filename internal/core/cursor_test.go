@@ -1016,7 +1016,7 @@ func TestCursor_Coordinates(t *testing.T) {
 				line: test.fields.line,
 			}
 
-			gotX, gotY := CoordinatesCursor(c, indent)
+			gotX, gotY := CoordinatesCursor(c, indent, nil)
 			if gotX != test.wantX {
 				t.Errorf("Cursor.Coordinates() gotX = %v, want %v", gotX, test.wantX)
 			}
@@ -1027,3 +1027,29 @@ func TestCursor_Coordinates(t *testing.T) {
 		})
 	}
 }
+
+func TestPositionAt(t *testing.T) {
+	indent := 2 // Assumes the prompt strings uses two columns
+
+	tests := []struct {
+		name string
+		x, y int
+		want int
+	}{
+		{name: "On the first row", x: indent + 4, y: 0, want: 4},
+		{name: "Before the line indentation", x: 0, y: 1, want: 16},
+		{name: "Past the end of a row", x: 79, y: 0, want: 15},
+		{name: "On an empty line", x: indent + 10, y: 2, want: 60},
+		{name: "Past the end of the line", x: 79, y: 9, want: len(cursorMultiline)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewCursor(&cursorMultiline)
+
+			if got := PositionAt(c, indent, nil, test.x, test.y); got != test.want {
+				t.Errorf("PositionAt() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
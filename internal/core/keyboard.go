@@ -0,0 +1,152 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"unicode"
+
+	"github.com/reeflective/readline/inputrc"
+	"github.com/reeflective/readline/internal/term"
+)
+
+// Keyboard protocols used by the terminal to report keys.
+const (
+	keyboardLegacy          = "legacy"
+	keyboardKitty           = "kitty"
+	keyboardModifyOtherKeys = "modifyOtherKeys"
+)
+
+// Modifiers reported in extended keys, but meaningless to binds.
+const lockModifiers = 64 | 128 // Caps lock, Num lock
+
+var (
+	// CSI code[:alternates] [; modifiers[:event]] [; text] u (kitty keyboard protocol).
+	// CSI 27 ; modifiers ; code ~ (xterm modifyOtherKeys).
+	rxExtendedKey = regexp.MustCompile(`\x1b\[(?:([0-9]+)(?::[0-9]*)*(?:;([0-9]+)(?::[0-9]+)?)?(?:;[0-9:]*)?u|27;([0-9]+);([0-9]+)~)`)
+
+	// CSI ? flags u, the answer to a kitty keyboard protocol query.
+	rxKittyFlags = regexp.MustCompile(`\x1b\[\?[0-9]+u`)
+)
+
+// Keys of the numeric keypad, reported as the keys they insert.
+var keypadKeys = map[rune]rune{
+	57399: '0', 57400: '1', 57401: '2', 57402: '3', 57403: '4',
+	57404: '5', 57405: '6', 57406: '7', 57407: '8', 57408: '9',
+	57409: '.', 57410: '/', 57411: '*', 57412: '-', 57413: '+',
+	57414: inputrc.Return, 57415: '=',
+}
+
+// EnableExtendedKeys asks the terminal to report keys with modifiers unambiguously:
+// with the kitty keyboard protocol if the terminal answers to its query, or with xterm's
+// modifyOtherKeys if it answers to other queries only. If it does not answer, legacy keys
+// are used. Returns the sequence restoring the terminal keyboard mode, to print when done.
+func (k *Keys) EnableExtendedKeys() (restore string) {
+	if k.keyboard == "" {
+		flags, attributes := k.QueryTerminal(term.KittyKeyboardQuery, rxKittyFlags)
+
+		switch {
+		case flags != nil:
+			k.keyboard = keyboardKitty
+		case attributes != nil:
+			k.keyboard = keyboardModifyOtherKeys
+		default:
+			k.keyboard = keyboardLegacy
+		}
+	}
+
+	switch k.keyboard {
+	case keyboardKitty:
+		fmt.Print(term.KittyKeyboardPush)
+		return term.KittyKeyboardPop
+	case keyboardModifyOtherKeys:
+		fmt.Print(term.ModifyOtherKeysStart)
+		return term.ModifyOtherKeysEnd
+	default:
+		return ""
+	}
+}
+
+// decodeExtendedKeys replaces keys reported with the kitty keyboard protocol or
+// xterm's modifyOtherKeys with their legacy sequences, when those are not ambiguous.
+// Other keys use the kitty protocol sequence, which are written \k{} in binds.
+func decodeExtendedKeys(keys []byte) []byte {
+	if !rxExtendedKey.Match(keys) {
+		return keys
+	}
+
+	return rxExtendedKey.ReplaceAllFunc(keys, func(seq []byte) []byte {
+		match := rxExtendedKey.FindSubmatch(seq)
+
+		code, mods := match[1], match[2]
+		if len(match[4]) > 0 {
+			code, mods = match[4], match[3]
+		}
+
+		key, _ := strconv.Atoi(string(code))
+		modifiers := 1
+
+		if len(mods) > 0 {
+			modifiers, _ = strconv.Atoi(string(mods))
+		}
+
+		return []byte(extendedKey(rune(key), (modifiers-1)&^lockModifiers))
+	})
+}
+
+// extendedKey returns the sequence of a key pressed with modifiers.
+func extendedKey(code rune, mods int) string {
+	if key, isKeypad := keypadKeys[code]; isKeypad {
+		code = key
+	}
+
+	if legacy, found := legacyKey(code, mods); found {
+		return legacy
+	}
+
+	return inputrc.ExtendedKey(code, mods)
+}
+
+// legacyKey returns the sequence of a key pressed with modifiers as sent by terminals
+// not using extended keys, if there is one and if it is not the one of another key.
+func legacyKey(code rune, mods int) (string, bool) {
+	// Private use area code points are functional keys without legacy sequences.
+	if code >= 0xe000 && code <= 0xf8ff {
+		return "", false
+	}
+
+	switch {
+	case mods == 0:
+		return string(code), true
+
+	case mods&inputrc.ModAlt != 0:
+		key, found := legacyKey(code, mods&^inputrc.ModAlt)
+		if !found {
+			return "", false
+		}
+
+		return string(inputrc.Esc) + key, true
+
+	case mods == inputrc.ModShift:
+		switch {
+		case code == inputrc.Tab:
+			return "\x1b[Z", true
+		case code == inputrc.Space:
+			return string(code), true
+		case unicode.IsLower(code):
+			return string(unicode.ToUpper(code)), true
+		}
+
+	case mods == inputrc.ModCtrl:
+		switch {
+		case code == 'i', code == 'm', code == '[':
+			return "", false
+		case code == inputrc.Space:
+			return string(rune(0)), true
+		case code >= 'a' && code <= 'z', code >= '@' && code <= '_':
+			return string(inputrc.Encontrol(code)), true
+		}
+	}
+
+	return "", false
+}
@@ -2,10 +2,13 @@ package core
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"regexp"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/rivo/uniseg"
 
@@ -15,6 +18,7 @@ import (
 
 const (
 	keyScanBufSize = 1024
+	queryTimeout   = 500 * time.Millisecond
 )
 
 // Stdin is used by the Keys struct to read and write keys.
@@ -22,29 +26,60 @@ const (
 // custom io.Readers, such as the one used on Windows.
 var Stdin io.ReadCloser = os.Stdin
 
-var rxRcvCursorPos = regexp.MustCompile(`\x1b\[([0-9]+);([0-9]+)R`)
+var (
+	rxRcvCursorPos     = regexp.MustCompile(`\x1b\[([0-9]+);([0-9]+)R`)
+	rxDeviceAttributes = regexp.MustCompile(`\x1b\[\?[0-9;]*c`)
+	rxMouseEvent       = regexp.MustCompile(`\x1b\[<([0-9]+);([0-9]+);([0-9]+)([Mm])`)
+)
+
+// Mouse buttons as reported in SGR mouse events (modifiers excluded).
+const (
+	MouseLeft      = 0
+	MouseMiddle    = 1
+	MouseRight     = 2
+	MouseWheelUp   = 64
+	MouseWheelDown = 65
+)
+
+// MouseEvent is a mouse button press or wheel event reported by the terminal.
+type MouseEvent struct {
+	Button int // Button code, including modifiers: only events bound to a command are reported.
+	X      int // Terminal column of the event, starting at 1.
+	Y      int // Terminal row of the event, starting at 1.
+}
 
 // Keys is used to read, manage and use keys input by the shell user.
 type Keys struct {
-	buf       []byte      // Keys read and waiting to be used.
-	matched   []rune      // Keys that have been successfully matched against a bind.
-	macroKeys []rune      // Keys that have been fed by a macro.
-	mustWait  bool        // Keys are in the stack, but we must still read stdin.
-	waiting   bool        // Currently waiting for keys on stdin.
-	reading   bool        // Currently reading keys out of the main loop.
-	keysOnce  chan []byte // Passing keys from the main routine.
-	cursor    chan []byte // Cursor coordinates has been read on stdin.
-	resize    chan bool   // Resize events on Windows are sent on stdin. USED IN WINDOWS
+	buf       []byte                 // Keys read and waiting to be used.
+	matched   []rune                 // Keys that have been successfully matched against a bind.
+	macroKeys []rune                 // Keys that have been fed by a macro.
+	mustWait  bool                   // Keys are in the stack, but we must still read stdin.
+	ambiguous bool                   // Keys in the stack are a complete sequence, but more keys might come.
+	expired   bool                   // No keys were read before keyseq-timeout, after ambiguous ones.
+	waiting   bool                   // Currently waiting for keys on stdin.
+	reading   bool                   // Currently reading keys out of the main loop.
+	keysOnce  chan []byte            // Passing keys from the main routine.
+	cursor    chan []byte            // Cursor coordinates has been read on stdin.
+	mouse     []MouseEvent           // Mouse events read on stdin, not yet used by a command.
+	isBound   func(keys string) bool // Returns true if a key sequence is bound in the active keymaps.
+	resize    chan bool              // Resize events on Windows are sent on stdin. USED IN WINDOWS
+	keyboard  string                 // Protocol used by the terminal to report keys, once queried.
+	detected  bool                   // The terminal has been queried for its capabilities.
 
 	eof   bool            // EOF has been reached.
 	cfg   *inputrc.Config // Configuration file used for meta key settings
 	mutex sync.RWMutex    // Concurrency safety
+	idle  sync.Mutex      // Held while running a function in the background, see WhileWaiting.
 }
 
 // WaitAvailableKeys waits until an input key is either read from standard input,
 // or directly returns if the key stack still/already has available keys.
 func WaitAvailableKeys(keys *Keys, cfg *inputrc.Config) {
 	keys.cfg = cfg
+	keys.expired = false
+
+	ambiguous := keys.ambiguous
+	keys.ambiguous = false
 
 	if len(keys.buf) > 0 && !keys.mustWait {
 		return
@@ -55,15 +90,28 @@ func WaitAvailableKeys(keys *Keys, cfg *inputrc.Config) {
 		return
 	}
 
+	// Ambiguous keys are used as they are if no
+	// other key is read before keyseq-timeout.
+	if ambiguous && !keys.waitInput(keyseqTimeout(cfg)) {
+		keys.mustWait = false
+		keys.expired = true
+
+		return
+	}
+
 	keys.mutex.Lock()
 	keys.waiting = true
 	keys.cursor = make(chan []byte)
 	keys.mutex.Unlock()
 
+	// Functions run while waiting must be done
+	// before the shell can use the keys read.
 	defer func() {
+		keys.idle.Lock()
 		keys.mutex.Lock()
 		keys.waiting = false
 		keys.mutex.Unlock()
+		keys.idle.Unlock()
 	}()
 
 	for {
@@ -109,6 +157,30 @@ func (k *Keys) IsEOF() bool {
 	return k.eof
 }
 
+// IsWaiting returns true if the shell is currently waiting for keys on stdin.
+func (k *Keys) IsWaiting() bool {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	return k.waiting
+}
+
+// WhileWaiting runs a function only if the shell is currently waiting for keys on stdin,
+// and returns true if it did. The shell does not run any command until the function
+// returns, so that the latter can safely use the shell state from another goroutine.
+func (k *Keys) WhileWaiting(run func()) bool {
+	k.idle.Lock()
+	defer k.idle.Unlock()
+
+	if !k.IsWaiting() {
+		return false
+	}
+
+	run()
+
+	return true
+}
+
 // PeekKey returns the first key in the stack, without removing it.
 func PeekKey(keys *Keys) (key byte, empty bool) {
 	switch {
@@ -210,6 +282,19 @@ func MatchedPrefix(keys *Keys, prefix ...byte) {
 	keys.matched = []rune(string(prefix))
 }
 
+// WaitKeyseq should be called after MatchedPrefix when the prefix keys are a complete
+// sequence on their own (eg. they also exactly match a bind): the next call to
+// WaitAvailableKeys will only wait for more keys until keyseq-timeout expires.
+func WaitKeyseq(keys *Keys) {
+	keys.ambiguous = keys.mustWait
+}
+
+// KeyseqExpired returns true if the keys in the stack have been waited for with
+// WaitKeyseq, and no other key has been read before keyseq-timeout expired.
+func KeyseqExpired(keys *Keys) bool {
+	return keys.expired
+}
+
 // PopForce is used to force-remove a key from the buffer, without marking
 // it as having matched a bind command. This is used, for example, when the
 // escape has been handled specially as a Vim escape.
@@ -346,3 +431,71 @@ func (k *Keys) extractCursorPos(keys []byte) (cursor, remain []byte) {
 
 	return
 }
+
+// PopMouse returns the oldest mouse event read on stdin and not yet used by a command.
+// Mouse events are dispatched as their coordinate-less key sequence, which is the key
+// sequence to bind to a command: this command can then retrieve the event with this.
+func PopMouse(keys *Keys) (event MouseEvent, found bool) {
+	keys.mutex.Lock()
+	defer keys.mutex.Unlock()
+
+	if len(keys.mouse) == 0 {
+		return event, false
+	}
+
+	event = keys.mouse[0]
+	keys.mouse = keys.mouse[1:]
+
+	return event, true
+}
+
+// filterInput decodes the mouse events and extended keys found in the input keys.
+func (k *Keys) filterInput(keys []byte) []byte {
+	return decodeExtendedKeys(k.extractMouse(keys))
+}
+
+// extractMouse replaces SGR mouse sequences with bindable sequences without the
+// event coordinates (eg. \x1b[<0M for a left click), and stores these events.
+// Releases, and events not bound to a command in the active keymaps (which use
+// the stored events) are dropped, since nothing would ever use them.
+func (k *Keys) extractMouse(keys []byte) []byte {
+	if !rxMouseEvent.Match(keys) {
+		return keys
+	}
+
+	return rxMouseEvent.ReplaceAllFunc(keys, func(seq []byte) []byte {
+		match := rxMouseEvent.FindSubmatch(seq)
+
+		button, _ := strconv.Atoi(string(match[1]))
+		x, _ := strconv.Atoi(string(match[2]))
+		y, _ := strconv.Atoi(string(match[3]))
+
+		event := fmt.Sprintf("\x1b[<%dM", button)
+
+		if string(match[4]) == "m" || (k.isBound != nil && !k.isBound(event)) {
+			return nil
+		}
+
+		k.mutex.Lock()
+		k.mouse = append(k.mouse, MouseEvent{Button: button, X: x, Y: y})
+		k.mutex.Unlock()
+
+		return []byte(event)
+	})
+}
+
+// SetBindChecker sets the function returning true if a key sequence is bound to
+// a command in the active keymaps: mouse events for which it returns false are
+// dropped when read. If nil, all mouse button presses and wheel events are kept.
+func (k *Keys) SetBindChecker(isBound func(keys string) bool) {
+	k.isBound = isBound
+}
+
+// keyseqTimeout returns the keyseq-timeout value, or 0 if it is not set.
+func keyseqTimeout(cfg *inputrc.Config) time.Duration {
+	if cfg == nil {
+		return 0
+	}
+
+	return time.Duration(max(0, cfg.GetInt("keyseq-timeout"))) * time.Millisecond
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestKeys_extractMouse(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantKeys   string
+		wantEvents []MouseEvent
+	}{
+		{
+			name:     "No mouse event",
+			input:    "abc\x1b[A",
+			wantKeys: "abc\x1b[A",
+		},
+		{
+			name:       "Click and release",
+			input:      "\x1b[<0;12;5M\x1b[<0;12;5m",
+			wantKeys:   "\x1b[<0M",
+			wantEvents: []MouseEvent{{Button: MouseLeft, X: 12, Y: 5}},
+		},
+		{
+			name:       "Wheel events among keys",
+			input:      "a\x1b[<64;1;2Mb\x1b[<65;3;4M",
+			wantKeys:   "a\x1b[<64Mb\x1b[<65M",
+			wantEvents: []MouseEvent{{Button: MouseWheelUp, X: 1, Y: 2}, {Button: MouseWheelDown, X: 3, Y: 4}},
+		},
+		{
+			name:     "Control click and motion",
+			input:    "\x1b[<16;7;8M\x1b[<32;9;8M",
+			wantKeys: "",
+		},
+		{
+			name:       "Bound modified click",
+			input:      "\x1b[<4;7;8M\x1b[<4;7;8m",
+			wantKeys:   "\x1b[<4M",
+			wantEvents: []MouseEvent{{Button: 4, X: 7, Y: 8}},
+		},
+		{
+			name:       "Right click then left click",
+			input:      "\x1b[<2;3;4M\x1b[<2;3;4m\x1b[<0;12;5M",
+			wantKeys:   "\x1b[<0M",
+			wantEvents: []MouseEvent{{Button: MouseLeft, X: 12, Y: 5}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys := &Keys{}
+			keys.SetBindChecker(func(keys string) bool {
+				return slices.Contains([]string{"\x1b[<0M", "\x1b[<4M", "\x1b[<64M", "\x1b[<65M"}, keys)
+			})
+
+			if got := string(keys.extractMouse([]byte(test.input))); got != test.wantKeys {
+				t.Errorf("extractMouse() = %q, want %q", got, test.wantKeys)
+			}
+
+			var events []MouseEvent
+
+			for {
+				event, found := PopMouse(keys)
+				if !found {
+					break
+				}
+
+				events = append(events, event)
+			}
+
+			if !reflect.DeepEqual(events, test.wantEvents) {
+				t.Errorf("PopMouse() events = %v, want %v", events, test.wantEvents)
+			}
+		})
+	}
+}
+
+func TestDecodeExtendedKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "Legacy keys", input: "a\x1b[A\t", want: "a\x1b[A\t"},
+		{name: "Escape", input: "\x1b[27u", want: "\x1b"},
+		{name: "Control letter", input: "\x1b[97;5u", want: "\x01"},
+		{name: "Control-I is not tab", input: "\x1b[105;5u", want: "\x1b[105;5u"},
+		{name: "Shift-Enter", input: "\x1b[13;2u", want: "\x1b[13;2u"},
+		{name: "Shift-Tab", input: "\x1b[9;2u", want: "\x1b[Z"},
+		{name: "Alt letter", input: "\x1b[102;3u", want: "\x1bf"},
+		{name: "Caps lock ignored", input: "\x1b[97;69u", want: "\x01"},
+		{name: "Keypad enter", input: "\x1b[57414u", want: "\r"},
+		{name: "Functional key", input: "\x1b[57428u", want: "\x1b[57428u"},
+		{name: "modifyOtherKeys Control-M", input: "\x1b[27;5;109~", want: "\x1b[109;5u"},
+		{name: "modifyOtherKeys Control-A", input: "x\x1b[27;5;97~y", want: "x\x01y"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := string(decodeExtendedKeys([]byte(test.input))); got != test.want {
+				t.Errorf("decodeExtendedKeys() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -7,7 +7,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"strconv"
+	"time"
+
+	"golang.org/x/sys/unix"
 
 	"github.com/reeflective/readline/internal/term"
 )
@@ -89,6 +93,78 @@ func (k *Keys) GetCursorPos() (x, y int) {
 	return x, y
 }
 
+// QueryTerminal prints a query to the terminal, followed by a request for its primary
+// device attributes (DA1), which all terminals answer after the query: once this answer
+// is read, the query cannot be answered anymore. Returns the answer matching the reply
+// expression, if any, and the device attributes, or nil if the terminal did not answer
+// in time. Other keys read while waiting for answers are kept as user input.
+func (k *Keys) QueryTerminal(query string, reply *regexp.Regexp) (answer, attributes []byte) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, nil
+	}
+
+	fmt.Print(query + term.DeviceAttributesQuery)
+
+	var input []byte
+
+	deadline := time.Now().Add(queryTimeout)
+
+	for attributes == nil {
+		remaining := time.Until(deadline)
+		if remaining <= 0 || !pollFile(os.Stdin, remaining) {
+			break
+		}
+
+		buf := make([]byte, keyScanBufSize)
+
+		read, err := os.Stdin.Read(buf)
+		if err != nil {
+			break
+		}
+
+		input = append(input, buf[:read]...)
+		attributes = rxDeviceAttributes.Find(input)
+	}
+
+	if reply != nil {
+		answer = reply.Find(input)
+		input = reply.ReplaceAll(input, nil)
+	}
+
+	input = k.filterInput(rxDeviceAttributes.ReplaceAll(input, nil))
+
+	k.mutex.Lock()
+	k.buf = append(k.buf, input...)
+	k.mutex.Unlock()
+
+	return answer, attributes
+}
+
+// waitInput returns true if some input can be read on stdin before the timeout expires.
+// Without timeout, or if stdin is not a file, it returns true without waiting.
+func (k *Keys) waitInput(timeout time.Duration) bool {
+	file, isFile := Stdin.(*os.File)
+	if timeout <= 0 || !isFile {
+		return true
+	}
+
+	return pollFile(file, timeout)
+}
+
+// pollFile returns true if some input can be read on a file before the timeout expires.
+func pollFile(file *os.File, timeout time.Duration) bool {
+	fds := []unix.PollFd{{Fd: int32(file.Fd()), Events: unix.POLLIN}}
+
+	for {
+		ready, err := unix.Poll(fds, int(max(1, timeout.Milliseconds())))
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+
+		return err != nil || ready > 0
+	}
+}
+
 func (k *Keys) readInputFiltered() (keys []byte, err error) {
 	// Start reading from os.Stdin in the background.
 	// We will either read keys from user, or an EOF
@@ -108,5 +184,5 @@ func (k *Keys) readInputFiltered() (keys []byte, err error) {
 		k.cursor <- cursor
 	}
 
-	return keys, nil
+	return k.filterInput(keys), nil
 }
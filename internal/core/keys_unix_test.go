@@ -0,0 +1,114 @@
+//go:build unix
+
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/reeflective/readline/inputrc"
+)
+
+func TestWaitAvailableKeys_keyseqTimeout(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer reader.Close()
+	defer writer.Close()
+
+	stdin := Stdin
+	Stdin = reader
+
+	defer func() { Stdin = stdin }()
+
+	cfg := inputrc.NewDefaultConfig()
+	cfg.Set("keyseq-timeout", 20)
+
+	keys := &Keys{}
+
+	// No key comes after the ambiguous one.
+	MatchedPrefix(keys, 'j')
+	WaitKeyseq(keys)
+	WaitAvailableKeys(keys, cfg)
+
+	if !KeyseqExpired(keys) {
+		t.Fatalf("KeyseqExpired() = false, want true")
+	}
+
+	if key, _ := PopKey(keys); key != 'j' {
+		t.Errorf("PopKey() = %q, want %q", key, 'j')
+	}
+
+	// A key comes before the timeout.
+	if _, err = writer.WriteString("k"); err != nil {
+		t.Fatal(err)
+	}
+
+	MatchedPrefix(keys, 'j')
+	WaitKeyseq(keys)
+	WaitAvailableKeys(keys, cfg)
+
+	if KeyseqExpired(keys) {
+		t.Errorf("KeyseqExpired() = true, want false")
+	}
+
+	if got := string(keys.buf); got != "jk" {
+		t.Errorf("keys = %q, want %q", got, "jk")
+	}
+}
+
+func TestKeys_WhileWaiting(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer reader.Close()
+	defer writer.Close()
+
+	stdin := Stdin
+	Stdin = reader
+
+	defer func() { Stdin = stdin }()
+
+	keys := &Keys{}
+
+	if keys.WhileWaiting(func() {}) {
+		t.Fatalf("WhileWaiting() = true while not waiting for keys")
+	}
+
+	done := make(chan bool)
+
+	go func() {
+		WaitAvailableKeys(keys, inputrc.NewDefaultConfig())
+		done <- true
+	}()
+
+	for !keys.IsWaiting() {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Keys read while the function runs are only used after it.
+	ran := keys.WhileWaiting(func() {
+		writer.Write([]byte("a"))
+
+		select {
+		case <-done:
+			t.Errorf("WaitAvailableKeys() returned while running a function")
+		case <-time.After(20 * time.Millisecond):
+		}
+	})
+
+	if !ran {
+		t.Errorf("WhileWaiting() = false while waiting for keys")
+	}
+
+	<-done
+
+	if key, _ := PopKey(keys); key != 'a' {
+		t.Errorf("PopKey() = %q, want 'a'", key)
+	}
+}
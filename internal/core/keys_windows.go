@@ -6,6 +6,8 @@ package core
 import (
 	"errors"
 	"io"
+	"regexp"
+	"time"
 	"unsafe"
 
 	"github.com/reeflective/readline/inputrc"
@@ -63,6 +65,19 @@ func GetTerminalResize(keys *Keys) <-chan bool {
 	return keys.resize
 }
 
+// QueryTerminal would print a query to the terminal and read its answer, but
+// console input records do not include terminal answers: it returns nothing.
+func (k *Keys) QueryTerminal(_ string, _ *regexp.Regexp) (answer, attributes []byte) {
+	return nil, nil
+}
+
+// waitInput returns true if some input can be read on stdin before the timeout expires.
+// Console input records include events that are not keys (such as key releases), so
+// waiting for them is not reliable: Windows always waits for the next key instead.
+func (k *Keys) waitInput(_ time.Duration) bool {
+	return true
+}
+
 // readInputFiltered on Windows needs to check for terminal resize events.
 func (k *Keys) readInputFiltered() (keys []byte, err error) {
 	for {
@@ -93,7 +108,7 @@ func (k *Keys) readInputFiltered() (keys []byte, err error) {
 			k.cursor <- cursor
 		}
 
-		return keys, nil
+		return k.filterInput(keys), nil
 	}
 }
 
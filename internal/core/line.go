@@ -277,7 +277,13 @@ func (l *Line) SurroundQuotes(single bool, pos int) (bpos, epos int) {
 // cursor position, assuming it is at the end of the shell prompt string.
 // Params:
 // @indent -    Used to align all lines (except the first) together on a single column.
-func DisplayLine(l *Line, indent int) {
+// @scroll -    If not nil, the line is displayed on a single row, from the scroll offset.
+func DisplayLine(l *Line, indent int, scroll *Scroll) {
+	if scroll.Scrolls(l) {
+		fmt.Print(scroll.Window(string(*l), indent) + color.BgDefault)
+		return
+	}
+
 	var builtLine strings.Builder
 	var lineLen int
 
@@ -317,10 +323,15 @@ func DisplayLine(l *Line, indent int) {
 // take into account an eventual suggestion added to the line before printing.
 // Params:
 // @indent - Coordinates to align all lines (except the first) together on a single column.
+// @scroll - If not nil, the line is displayed on a single row, from the scroll offset.
 // Returns:
 // @x - The number of columns, starting from the terminal left, to the end of the last line.
 // @y - The number of actual lines on which the line spans, accounting for line wrap.
-func CoordinatesLine(l *Line, indent int) (int, int) {
+func CoordinatesLine(l *Line, indent int, scroll *Scroll) (int, int) {
+	if scroll.Scrolls(l) {
+		return scroll.coordinates(l, l.Len(), indent), 0
+	}
+
 	var usedY, usedX, lineStart, lineIdx int
 
 	for i, r := range *l {
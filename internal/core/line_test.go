@@ -1098,7 +1098,7 @@ func TestDisplayLine(t *testing.T) {
 		os.Stdout = w
 
 		t.Run(tt.name, func(t *testing.T) {
-			DisplayLine(tt.l, tt.args.indent)
+			DisplayLine(tt.l, tt.args.indent, nil)
 		})
 
 		w.Close()
@@ -1160,7 +1160,7 @@ func TestCoordinatesLine(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			gotX, gotY := CoordinatesLine(test.l, test.args.indent)
+			gotX, gotY := CoordinatesLine(test.l, test.args.indent, nil)
 			if gotX != test.wantX {
 				t.Errorf("CoordinatesLine() gotX = %v, want %v", gotX, test.wantX)
 			}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/reeflective/readline/internal/color"
+	"github.com/reeflective/readline/internal/strutil"
+	"github.com/reeflective/readline/internal/term"
+)
+
+// Horizontal scrolling indicators, displayed in place of
+// the first/last columns when the line is hidden before/after.
+const (
+	ScrollLeft  = "<"
+	ScrollRight = ">"
+)
+
+// Scroll is the horizontal scrolling of an input line displayed on a single terminal
+// row, as in horizontal-scroll-mode: only the columns of the line starting at Offset
+// and fitting in the terminal width are displayed, with indicators in place of the
+// first/last ones when the line is hidden before/after them. A nil scroll, or a line
+// containing newlines, is displayed on as many rows as needed instead.
+type Scroll struct {
+	Offset int
+}
+
+// Scrolls returns true if the line is displayed with horizontal scrolling.
+func (s *Scroll) Scrolls(l *Line) bool {
+	return s != nil && l.Lines() == 0
+}
+
+// Update adjusts the offset so that the cursor is displayed, but not on an indicator.
+// When the cursor went out of sight, the line is scrolled to put it in the middle of
+// the row, and when the entire line fits in the row, it is not scrolled at all.
+func (s *Scroll) Update(cur *Cursor, indent int) {
+	if !s.Scrolls(cur.line) {
+		return
+	}
+
+	cur.CheckAppend()
+
+	width := s.width(indent)
+	lineWidth := strutil.RealLength(string(*cur.line))
+	cursor := strutil.RealLength(string((*cur.line)[:cur.pos]))
+
+	if lineWidth <= width {
+		s.Offset = 0
+		return
+	}
+
+	if first, last := s.visible(lineWidth, width); cursor < first || cursor > last {
+		s.Offset = max(0, cursor-width/2)
+	}
+}
+
+// Window returns the part of a line (which may contain escape sequences) displayed
+// on the row from the scroll offset, with indicators where the line is hidden.
+func (s *Scroll) Window(line string, indent int) string {
+	line, _, _ = strings.Cut(line, "\n")
+
+	width := s.width(indent)
+	lineWidth := strutil.RealLength(line)
+	start, end := s.Offset, s.Offset+width
+
+	var left, right string
+
+	if s.Offset > 0 {
+		left = color.Reset + ScrollLeft
+		start++
+	}
+
+	if lineWidth > end {
+		right = color.Reset + ScrollRight
+		end--
+	}
+
+	return left + strutil.SliceColumns(line, start, end) + right + color.Reset
+}
+
+// width returns the number of columns in which the line is scrolled, leaving
+// the last one free so that the cursor can be displayed after the line's end.
+func (s *Scroll) width(indent int) int {
+	return max(term.GetWidth()-indent-1, 3)
+}
+
+// visible returns the first and last columns of the line on which the cursor
+// can be displayed, that is, those of the row not used by indicators.
+func (s *Scroll) visible(lineWidth, width int) (first, last int) {
+	first, last = s.Offset, s.Offset+width-1
+
+	if s.Offset > 0 {
+		first++
+	}
+
+	if lineWidth > s.Offset+width {
+		last--
+	}
+
+	return first, last
+}
+
+// coordinates returns the column of a position of the line when scrolled.
+func (s *Scroll) coordinates(l *Line, pos, indent int) int {
+	column := strutil.RealLength(string((*l)[:pos])) - s.Offset
+
+	return indent + min(max(column, 0), s.width(indent))
+}
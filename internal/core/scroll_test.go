@@ -0,0 +1,107 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/reeflective/readline/internal/color"
+)
+
+func TestScroll(t *testing.T) {
+	indent := 9 // Leaves 70 columns for the line in a 80-columns terminal.
+
+	long := Line(strings.Repeat("0123456789", 10))
+	short := Line("ls -l")
+	fits := Line(strings.Repeat("0123456789", 7))
+
+	tests := []struct {
+		name       string
+		line       *Line
+		pos        int
+		offset     int
+		wantOffset int
+		wantX      int
+		wantEndX   int
+		wantWindow string
+	}{
+		{
+			name:       "Short line",
+			line:       &short,
+			pos:        5,
+			offset:     3,
+			wantOffset: 0,
+			wantX:      indent + 5,
+			wantEndX:   indent + 5,
+			wantWindow: "ls -l" + color.Reset,
+		},
+		{
+			name:       "Line as wide as the row",
+			line:       &fits,
+			pos:        70,
+			offset:     3,
+			wantOffset: 0,
+			wantX:      indent + 70,
+			wantEndX:   indent + 70,
+			wantWindow: string(fits) + color.Reset,
+		},
+		{
+			name:       "Line hidden after the row",
+			line:       &long,
+			pos:        0,
+			wantOffset: 0,
+			wantX:      indent,
+			wantEndX:   indent + 70,
+			wantWindow: string(long[:69]) + color.Reset + ScrollRight + color.Reset,
+		},
+		{
+			name:       "Cursor out of sight",
+			line:       &long,
+			pos:        100,
+			wantOffset: 65,
+			wantX:      indent + 35,
+			wantEndX:   indent + 35,
+			wantWindow: color.Reset + ScrollLeft + string(long[66:]) + color.Reset,
+		},
+		{
+			name:       "Cursor in sight",
+			line:       &long,
+			pos:        50,
+			offset:     20,
+			wantOffset: 20,
+			wantX:      indent + 30,
+			wantEndX:   indent + 70,
+			wantWindow: color.Reset + ScrollLeft + string(long[21:89]) + color.Reset + ScrollRight + color.Reset,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scroll := &Scroll{Offset: test.offset}
+			cur := NewCursor(test.line)
+			cur.Set(test.pos)
+
+			scroll.Update(cur, indent)
+
+			if scroll.Offset != test.wantOffset {
+				t.Errorf("Scroll.Update() offset = %v, want %v", scroll.Offset, test.wantOffset)
+			}
+
+			if x, y := CoordinatesCursor(cur, indent, scroll); x != test.wantX || y != 0 {
+				t.Errorf("CoordinatesCursor() = %v, %v, want %v, 0", x, y, test.wantX)
+			}
+
+			if x, y := CoordinatesLine(test.line, indent, scroll); x != test.wantEndX || y != 0 {
+				t.Errorf("CoordinatesLine() = %v, %v, want %v, 0", x, y, test.wantEndX)
+			}
+
+			if window := scroll.Window(string(*test.line), indent); window != test.wantWindow {
+				t.Errorf("Scroll.Window() = %q, want %q", window, test.wantWindow)
+			}
+		})
+	}
+
+	multiline := Line("ls\n-l")
+	if (&Scroll{}).Scrolls(&multiline) || (*Scroll)(nil).Scrolls(&short) {
+		t.Errorf("Scroll.Scrolls() = true for a multiline line or a nil scroll")
+	}
+}
@@ -0,0 +1,115 @@
+package display
+
+import (
+	"github.com/reeflective/readline/internal/color"
+	"github.com/reeflective/readline/internal/term"
+)
+
+// Severity is the severity of a diagnostic, which determines its style.
+type Severity int
+
+// Diagnostic severities, from the least to the most severe.
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+// Diagnostic is a problem found by a validator in the input line, on
+// a range of runes from Start (included) to End (excluded).
+type Diagnostic struct {
+	Start    int
+	End      int
+	Severity Severity
+	Message  string
+}
+
+// validation is the result of the validator for a given line.
+type validation struct {
+	line        string
+	diagnostics []Diagnostic
+}
+
+// Diagnostics returns the diagnostics produced by the validator for a line,
+// which are only computed again when the line has changed since last time.
+func (e *Engine) Diagnostics(line []rune) []Diagnostic {
+	if e.validator == nil {
+		return nil
+	}
+
+	if e.validation == nil || e.validation.line != string(line) {
+		e.validation = &validation{
+			line:        string(line),
+			diagnostics: e.validator(line),
+		}
+	}
+
+	return e.validation.diagnostics
+}
+
+// diagnosticStyles adds the style of all diagnostic ranges to the styles of a line.
+func (e *Engine) diagnosticStyles(line []rune, styles []string) {
+	for _, diag := range e.Diagnostics(line) {
+		style := diagnosticStyle(diag.Severity)
+
+		for i := max(diag.Start, 0); i < min(diag.End, len(line)); i++ {
+			styles[i] += style
+		}
+	}
+}
+
+// diagnosticStyle returns the style of a diagnostic range: a curly underline colored
+// after its severity if the terminal supports it, or a straight underline otherwise.
+func diagnosticStyle(severity Severity) string {
+	if !term.Caps.Undercurl {
+		return color.Underscore
+	}
+
+	var underline string
+
+	switch severity {
+	case SeverityError:
+		underline = "58;5;1"
+	case SeverityWarning:
+		underline = "58;5;3"
+	default:
+		underline = "58;5;4"
+	}
+
+	return color.Undercurl + color.Fmt(underline)
+}
+
+// renderDiagnostic returns the message of the most severe diagnostic under the
+// cursor (or just before it), styled after its severity, or nothing if none.
+func (e *Engine) renderDiagnostic() string {
+	cpos := e.cursor.Pos()
+
+	var found *Diagnostic
+
+	for i, diag := range e.Diagnostics(*e.line) {
+		if cpos < diag.Start || cpos > diag.End || diag.Message == "" {
+			continue
+		}
+
+		if found == nil || diag.Severity > found.Severity {
+			found = &e.validation.diagnostics[i]
+		}
+	}
+
+	if found == nil {
+		return ""
+	}
+
+	var style string
+
+	switch found.Severity {
+	case SeverityError:
+		style = e.opts.GetString("diagnostic-error-style")
+	case SeverityWarning:
+		style = e.opts.GetString("diagnostic-warning-style")
+	default:
+		style = e.opts.GetString("diagnostic-info-style")
+	}
+
+	return color.UnquoteRC(style) + found.Message + color.Reset
+}
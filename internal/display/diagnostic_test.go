@@ -0,0 +1,58 @@
+package display
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/reeflective/readline/internal/term"
+)
+
+func TestDiagnostics(t *testing.T) {
+	defer func(caps term.Capabilities) { term.Caps = caps }(term.Caps)
+
+	var calls int
+
+	eng := &Engine{validator: func(line []rune) []Diagnostic {
+		calls++
+		return []Diagnostic{
+			{Start: 0, End: 2, Severity: SeverityError, Message: "unknown command"},
+			{Start: 3, End: 10, Severity: SeverityWarning},
+		}
+	}}
+
+	eng.Diagnostics([]rune("ls -l"))
+	eng.Diagnostics([]rune("ls -l"))
+
+	if calls != 1 {
+		t.Errorf("Diagnostics() called the validator %d times for the same line, want 1", calls)
+	}
+
+	eng.Diagnostics([]rune("ls -la"))
+
+	if calls != 2 {
+		t.Errorf("Diagnostics() called the validator %d times for two lines, want 2", calls)
+	}
+
+	term.Caps = term.Capabilities{Colors: term.Color256, Undercurl: true}
+	styles := make([]string, 5)
+	eng.diagnosticStyles([]rune("ls -l"), styles)
+
+	want := []string{
+		"\x1b[4:3m\x1b[58;5;1m", "\x1b[4:3m\x1b[58;5;1m", "",
+		"\x1b[4:3m\x1b[58;5;3m", "\x1b[4:3m\x1b[58;5;3m",
+	}
+
+	if !reflect.DeepEqual(styles, want) {
+		t.Errorf("diagnosticStyles() = %q, want %q", styles, want)
+	}
+
+	term.Caps = term.Capabilities{Colors: term.Color16}
+	styles = make([]string, 5)
+	eng.diagnosticStyles([]rune("ls -l"), styles)
+
+	want = []string{"\x1b[4m", "\x1b[4m", "", "\x1b[4m", "\x1b[4m"}
+
+	if !reflect.DeepEqual(styles, want) {
+		t.Errorf("diagnosticStyles() without undercurl = %q, want %q", styles, want)
+	}
+}
@@ -2,6 +2,8 @@ package display
 
 import (
 	"fmt"
+	"slices"
+	"sync"
 
 	"github.com/reeflective/readline/inputrc"
 	"github.com/reeflective/readline/internal/color"
@@ -22,16 +24,24 @@ var (
 // interface and stores the necessary offsets of each components.
 type Engine struct {
 	// Operating parameters
-	highlighter    func(line []rune) string
-	startCols      int
-	startRows      int
-	lineCol        int
-	lineRows       int
-	cursorRow      int
-	cursorCol      int
-	hintRows       int
-	compRows       int
-	primaryPrinted bool
+	highlighter     func(line []rune) string
+	spanHighlighter func(line []rune) []Span
+	spans           map[string][]Span
+	validator       func(line []rune) []Diagnostic
+	validation      *validation
+	scroll          core.Scroll
+	startCols       int
+	startRows       int
+	lineCol         int
+	lineRows        int
+	cursorRow       int
+	cursorCol       int
+	hintRows        int
+	compRows        int
+	toolbarRows     int
+	primaryPrinted  bool
+	frame           *frame
+	mutex           sync.Mutex
 
 	// UI components
 	keys      *core.Keys
@@ -60,10 +70,16 @@ func NewEngine(k *core.Keys, s *core.Selection, h *history.Sources, p *ui.Prompt
 }
 
 // Init computes some base coordinates needed before displaying the line and helpers.
-// The shell syntax highlighter is also provided here, since any consumer library will
-// have bound it after instantiating a new shell instance.
-func Init(e *Engine, highlighter func([]rune) string) {
+// The shell syntax highlighters and validator are also provided here, since any consumer
+// library will have bound them after instantiating a new shell instance. The span
+// highlighter, if not nil, is used instead of the other one, and the spans and
+// diagnostics computed so far are dropped.
+func Init(e *Engine, highlighter func([]rune) string, spanHighlighter func([]rune) []Span, validator func([]rune) []Diagnostic) {
 	e.highlighter = highlighter
+	e.spanHighlighter = spanHighlighter
+	e.spans = nil
+	e.validator = validator
+	e.validation = nil
 }
 
 // PrintPrimaryPrompt redraws the primary prompt.
@@ -133,8 +149,11 @@ func (e *Engine) RefreshTransient() {
 
 // CursorToLineStart moves the cursor just after the primary prompt.
 // This function should only be called when the cursor is on its
-// "cursor" position on the input line.
+// "cursor" position on the input line, and the next refresh will
+// redraw the entire interface.
 func (e *Engine) CursorToLineStart() {
+	e.frame = nil
+
 	term.MoveCursorBackwards(e.cursorCol)
 	term.MoveCursorUp(e.cursorRow)
 	term.MoveCursorForwards(e.startCols)
@@ -143,8 +162,11 @@ func (e *Engine) CursorToLineStart() {
 // CursorBelowLine moves the cursor to the leftmost
 // column of the first row after the last line of input.
 // This function should only be called when the cursor
-// is on its "cursor" position on the input line.
+// is on its "cursor" position on the input line, and the
+// next refresh will redraw the entire interface.
 func (e *Engine) CursorBelowLine() {
+	e.frame = nil
+
 	term.MoveCursorUp(e.cursorRow)
 	term.MoveCursorDown(e.lineRows)
 	fmt.Print(term.NewlineReturn)
@@ -167,13 +189,7 @@ func (e *Engine) cursorHintToLineStart() {
 }
 
 func (e *Engine) computeCoordinates(suggested bool) {
-	// Get the new input line and auto-suggested one.
-	e.line, e.cursor = e.completer.Line()
-	if e.completer.IsInserting() {
-		e.suggested = *e.line
-	} else {
-		e.suggested = e.histories.Suggest(e.line)
-	}
+	e.updateLine()
 
 	// Get the position of the line's beginning by querying
 	// the terminal for the cursor position.
@@ -188,54 +204,87 @@ func (e *Engine) computeCoordinates(suggested bool) {
 		e.startCols = e.prompt.LastUsed()
 	}
 
-	e.cursorCol, e.cursorRow = core.CoordinatesCursor(e.cursor, e.startCols)
+	e.computeLineCoordinates(suggested)
+
+	e.primaryPrinted = false
+}
+
+// updateLine gets the new input line and auto-suggested one.
+func (e *Engine) updateLine() {
+	e.line, e.cursor = e.completer.Line()
+	if e.completer.IsInserting() {
+		e.suggested = *e.line
+	} else {
+		e.suggested = e.histories.Suggest(e.line)
+	}
+
+	// Scrolled lines are displayed on a single row, suggestions included.
+	if e.horizontalScroll().Scrolls(e.line) {
+		if end := slices.Index(e.suggested, '\n'); end >= 0 {
+			e.suggested = e.suggested[:end]
+		}
+	}
+}
+
+// horizontalScroll returns the horizontal scrolling of the
+// line if horizontal-scroll-mode is enabled, or nil otherwise.
+func (e *Engine) horizontalScroll() *core.Scroll {
+	if !e.opts.GetBool("horizontal-scroll-mode") {
+		return nil
+	}
+
+	return &e.scroll
+}
+
+// computeLineCoordinates computes the cursor position and the end
+// of the input line, relatively to the start of the input area.
+func (e *Engine) computeLineCoordinates(suggested bool) {
+	scroll := e.horizontalScroll()
+	scroll.Update(e.cursor, e.startCols)
+
+	e.cursorCol, e.cursorRow = core.CoordinatesCursor(e.cursor, e.startCols, scroll)
 
 	// Get the number of rows used by the line, and the end line X pos.
 	if e.opts.GetBool("history-autosuggest") && suggested {
-		e.lineCol, e.lineRows = core.CoordinatesLine(&e.suggested, e.startCols)
+		e.lineCol, e.lineRows = core.CoordinatesLine(&e.suggested, e.startCols, scroll)
 	} else {
-		e.lineCol, e.lineRows = core.CoordinatesLine(e.line, e.startCols)
+		e.lineCol, e.lineRows = core.CoordinatesLine(e.line, e.startCols, scroll)
 	}
-
-	e.primaryPrinted = false
 }
 
 func (e *Engine) displayLine() {
-	var line string
+	// Format tabs as spaces, for consistent display
+	line := strutil.FormatTabs(e.renderLine()) + term.ClearLineAfter
 
-	// Apply user-defined highlighter to the input line.
-	if e.highlighter != nil {
-		line = e.highlighter(*e.line)
-	} else {
-		line = string(*e.line)
+	// And display the line.
+	e.suggested.Set([]rune(line)...)
+	core.DisplayLine(&e.suggested, e.startCols, e.horizontalScroll())
+
+	// Adjust the cursor if the line fits exactly in the terminal width.
+	if e.lineCol == 0 {
+		fmt.Print(term.NewlineReturn)
+		fmt.Print(term.ClearLineAfter)
 	}
+}
 
+// renderLine returns the input line with all its highlighting
+// applied, followed by the auto-suggested line if any.
+func (e *Engine) renderLine() string {
 	// Highlight matching parenthesis
 	if e.opts.GetBool("blink-matching-paren") {
 		core.HighlightMatchers(e.selection)
 		defer core.ResetMatchers(e.selection)
 	}
 
-	// Apply visual selections highlighting if any
-	line = e.highlightLine([]rune(line), *e.selection)
+	// Apply syntax and visual selections highlighting if any
+	line := e.highlightLine(*e.line, *e.selection)
 
 	// Get the subset of the suggested line to print.
 	if len(e.suggested) > e.line.Len() && e.opts.GetBool("history-autosuggest") {
 		line += color.Dim + color.Fmt(color.Fg+"242") + string(e.suggested[e.line.Len():]) + color.Reset
 	}
 
-	// Format tabs as spaces, for consistent display
-	line = strutil.FormatTabs(line) + term.ClearLineAfter
-
-	// And display the line.
-	e.suggested.Set([]rune(line)...)
-	core.DisplayLine(&e.suggested, e.startCols)
-
-	// Adjust the cursor if the line fits exactly in the terminal width.
-	if e.lineCol == 0 {
-		fmt.Print(term.NewlineReturn)
-		fmt.Print(term.ClearLineAfter)
-	}
+	return line
 }
 
 // lineEndToCursorPos moves the cursor from the end of the input line
@@ -249,11 +298,12 @@ func (e *Engine) lineEndToCursorPos() {
 	term.MoveCursorForwards(e.cursorCol)
 }
 
-// AvailableHelperLines returns the number of lines available below the hint section.
-// It returns half the terminal space if we currently have less than 1/3rd of it below.
+// AvailableHelperLines returns the number of lines available below the hint section,
+// excluding those reserved for the status line. It returns half the terminal space if
+// we currently have less than 1/3rd of it below.
 func (e *Engine) AvailableHelperLines() int {
 	termHeight := term.GetLength()
-	compLines := termHeight - e.startRows - e.lineRows - e.hintRows
+	compLines := termHeight - e.startRows - e.lineRows - e.hintRows - e.toolbarRows
 
 	if compLines < (termHeight / oneThirdTerminalHeight) {
 		compLines = (termHeight / halfTerminalHeight)
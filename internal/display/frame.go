@@ -0,0 +1,180 @@
+package display
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rivo/uniseg"
+
+	"github.com/reeflective/readline/internal/color"
+	"github.com/reeflective/readline/internal/strutil"
+	"github.com/reeflective/readline/internal/term"
+)
+
+var (
+	// CSI, OSC (terminated by BEL or ST) and two-bytes escape sequences.
+	rxEscape = regexp.MustCompile(`^\x1b(?:\[[0-9;:?<=>]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[^\[\]])`)
+
+	rxSGR       = regexp.MustCompile(`^\x1b\[([0-9;:]*)m$`)
+	rxClearLine = regexp.MustCompile(`^\x1b\[[0-2]?K$`)
+)
+
+// frame is the model of the interface rendered by the last refresh: the terminal
+// rows from the last line of the primary prompt down to the last helper row, which
+// are compared with the new ones so that only the rows that changed are printed.
+type frame struct {
+	rows  []string
+	width int
+}
+
+// splitRows splits a rendered string into the terminal rows on which it spans. Each
+// row starts with the SGR effects active at the end of the previous one, and ends with
+// a reset if effects are still active, so that it can be printed alone. Line clearing
+// sequences are dropped, since they are added when printing rows. If not nil, the
+// prefix function returns a string starting each line after a newline.
+func splitRows(text string, width int, prefix func(line int) string) (rows []string) {
+	var row strings.Builder
+	var effects string
+	var column, line int
+
+	text = strutil.FormatTabs(text)
+
+	newRow := func() {
+		if effects != "" {
+			row.WriteString(color.Reset)
+		}
+
+		rows = append(rows, row.String())
+		row.Reset()
+		column = 0
+	}
+
+	for len(text) > 0 {
+		if seq := rxEscape.FindString(text); seq != "" {
+			text = text[len(seq):]
+
+			effects = addEffects(effects, seq)
+
+			if !rxClearLine.MatchString(seq) {
+				row.WriteString(seq)
+			}
+
+			continue
+		}
+
+		switch text[0] {
+		case '\r':
+			text = text[1:]
+			continue
+		case '\n':
+			text = text[1:]
+			line++
+
+			newRow()
+
+			if prefix != nil {
+				start := prefix(line)
+				row.WriteString(start)
+				column = strutil.RealLength(start)
+			}
+
+			row.WriteString(effects)
+
+			continue
+		}
+
+		cluster, rest, clusterWidth, _ := uniseg.FirstGraphemeClusterInString(text, -1)
+		text = rest
+
+		if column+clusterWidth > width && column > 0 {
+			newRow()
+			row.WriteString(effects)
+		}
+
+		row.WriteString(cluster)
+		column += clusterWidth
+	}
+
+	newRow()
+
+	return rows
+}
+
+// addEffects returns the SGR effects active after an escape sequence.
+func addEffects(effects, seq string) string {
+	sgr := rxSGR.FindStringSubmatch(seq)
+	if sgr == nil {
+		return effects
+	}
+
+	if params := sgr[1]; params == "" || params == "0" || strings.HasPrefix(params, "0;") {
+		effects = ""
+	}
+
+	if params := sgr[1]; params != "" && params != "0" {
+		effects += seq
+	}
+
+	return effects
+}
+
+// drawRows writes the rows that changed since the previous frame (or all of them
+// if there is none), starting with the cursor on the first row and returning the
+// row on which the cursor is left. Rows of the previous frame below the new ones
+// are cleared, as is everything below the frame when redrawing it entirely.
+func drawRows(out *strings.Builder, rows []string, previous *frame, width int) (row int) {
+	var old []string
+	if previous != nil {
+		old = previous.rows
+	}
+
+	last := len(rows) - 1
+	clearBelow := previous == nil || len(old) > len(rows)
+
+	for i, content := range rows {
+		unchanged := i < len(old) && old[i] == content
+		if unchanged && (i != last || !clearBelow) {
+			continue
+		}
+
+		moveRows(out, row, i, max(len(old), row+1))
+		row = i
+
+		out.WriteString("\r")
+
+		// The last row is cleared before being printed, since clearing
+		// after a row using all columns would also delete its last one.
+		if i == last && clearBelow {
+			out.WriteString(term.ClearScreenBelow + content)
+			continue
+		}
+
+		out.WriteString(content)
+
+		if strutil.RealLength(content) < width {
+			out.WriteString(term.ClearLineAfter)
+		}
+	}
+
+	return row
+}
+
+// moveRows moves the cursor between two rows of the frame, knowing how many rows
+// are already printed. Moving below those is done with newlines, so that the
+// terminal scrolls if rows must be added at the bottom of it.
+func moveRows(out *strings.Builder, from, to, printed int) {
+	if to < from {
+		fmt.Fprintf(out, "\x1b[%dA", from-to)
+		return
+	}
+
+	if down := min(to, printed-1) - from; down > 0 {
+		fmt.Fprintf(out, "\x1b[%dB", down)
+		from += down
+	}
+
+	if to > from {
+		out.WriteString(strings.Repeat("\n", to-from))
+	}
+}
@@ -0,0 +1,129 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitRows(t *testing.T) {
+	indicator := func(line int) string { return "| " }
+
+	tests := []struct {
+		name   string
+		text   string
+		width  int
+		prefix func(line int) string
+		want   []string
+	}{
+		{
+			name:  "Single row",
+			text:  "> echo",
+			width: 10,
+			want:  []string{"> echo"},
+		},
+		{
+			name:  "Wrapped row",
+			text:  "> echo hello",
+			width: 8,
+			want:  []string{"> echo h", "ello"},
+		},
+		{
+			name:  "Exact width",
+			text:  "12345678",
+			width: 8,
+			want:  []string{"12345678"},
+		},
+		{
+			name:  "Effects continued on next row",
+			text:  "> \x1b[31mecho\x1b[0m ok",
+			width: 4,
+			want:  []string{"> \x1b[31mec\x1b[0m", "\x1b[31mho\x1b[0m o", "k"},
+		},
+		{
+			name:  "Line clearing removed",
+			text:  "hint\x1b[0K\r\nnext",
+			width: 10,
+			want:  []string{"hint", "next"},
+		},
+		{
+			name:   "Line prefixes",
+			text:   "> if\n\x1b[1mthen\nfi",
+			width:  10,
+			prefix: indicator,
+			want:   []string{"> if", "| \x1b[1mthen\x1b[0m", "| \x1b[1mfi\x1b[0m"},
+		},
+		{
+			name:  "Wide characters",
+			text:  "ab日本",
+			width: 5,
+			want:  []string{"ab日", "本"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := splitRows(test.text, test.width, test.prefix)
+			if strings.Join(got, "|") != strings.Join(test.want, "|") {
+				t.Errorf("splitRows() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDrawRows(t *testing.T) {
+	previous := &frame{rows: []string{"> ech", "hint", "comp1", "comp2"}, width: 10}
+
+	tests := []struct {
+		name     string
+		rows     []string
+		previous *frame
+		want     string
+		wantRow  int
+	}{
+		{
+			name:    "Full redraw",
+			rows:    []string{"> echo", "hint"},
+			want:    "\r> echo\x1b[0K\n\r\x1b[0Jhint",
+			wantRow: 1,
+		},
+		{
+			name:     "Changed rows only",
+			rows:     []string{"> echo", "hint", "comp1", "comp3"},
+			previous: previous,
+			want:     "\r> echo\x1b[0K\x1b[3B\rcomp3\x1b[0K",
+			wantRow:  3,
+		},
+		{
+			name:     "Fewer rows",
+			rows:     []string{"> ech", "hint"},
+			previous: previous,
+			want:     "\x1b[1B\r\x1b[0Jhint",
+			wantRow:  1,
+		},
+		{
+			name:     "More rows",
+			rows:     []string{"> ech", "hint", "comp1", "comp2", "comp3"},
+			previous: previous,
+			want:     "\x1b[3B\n\rcomp3\x1b[0K",
+			wantRow:  4,
+		},
+		{
+			name:     "No changes",
+			rows:     []string{"> ech", "hint", "comp1", "comp2"},
+			previous: previous,
+			want:     "",
+			wantRow:  0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out strings.Builder
+
+			row := drawRows(&out, test.rows, test.previous, 10)
+			if out.String() != test.want || row != test.wantRow {
+				t.Errorf("drawRows() = %q, %d, want %q, %d", out.String(), row, test.want, test.wantRow)
+			}
+		})
+	}
+}
@@ -1,51 +1,89 @@
 package display
 
 import (
-	"fmt"
 	"regexp"
 	"sort"
-	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/reeflective/readline/internal/color"
 	"github.com/reeflective/readline/internal/core"
 )
 
-// highlightLine applies visual/selection highlighting to a line.
-// The provided line might already have been highlighted by a user-provided
-// highlighter: this function accounts for any embedded color sequences.
+// highlightLine returns the line highlighted by the user-provided highlighter, over
+// which are merged comments, diagnostics, incremental search matches and visual/
+// surround/matcher selections, in increasing order of precedence. All of these are
+// styles of runes, so that they do not have to account for each other's sequences.
 func (e *Engine) highlightLine(line []rune, selection core.Selection) string {
-	// Sort regions and extract colors/positions.
-	sorted := sortHighlights(selection)
-	colors := e.getHighlights(line, sorted)
+	styles := e.syntaxStyles(line)
 
-	var highlighted string
+	// Comments override any syntax highlighting.
+	comment := strings.Trim(e.opts.GetString("comment-begin"), "\"")
+	commentPattern := `(^|\s)` + regexp.QuoteMeta(comment) + `.*`
 
-	// And apply highlighting before each rune.
-	var highlightedSb25 strings.Builder
+	if commentsMatch, err := regexp.Compile(commentPattern); err == nil && comment != "" {
+		commentColor := color.Fmt(color.Fg + "244")
 
-	for i, r := range line {
-		if highlight, found := colors[i]; found {
-			highlightedSb25.WriteString(string(highlight))
+		for _, match := range runeMatches(commentsMatch, line) {
+			for i := match[0]; i < match[1]; i++ {
+				styles[i] = commentColor
+			}
 		}
+	}
+
+	// Ranges of the line diagnosed by the validator.
+	e.diagnosticStyles(line, styles)
 
-		highlightedSb25.WriteRune(r)
+	// Matches of the incremental search, when the line is a search result.
+	if search := e.completer.IsearchLineRegex(); search != nil {
+		for _, match := range runeMatches(search, line) {
+			for i := match[0]; i < match[1]; i++ {
+				styles[i] += color.Underscore
+			}
+		}
 	}
 
-	highlighted += highlightedSb25.String()
+	// And selections, with the most recently started ones on top.
+	for _, region := range sortHighlights(selection) {
+		bpos, epos := region.Pos()
+		style := regionStyle(region, e.opts.GetString("active-region-start-color"))
 
-	// Finally, highlight comments using a regex.
-	comment := strings.Trim(e.opts.GetString("comment-begin"), "\"")
-	commentPattern := fmt.Sprintf(`(^|\s)%s.*`, comment)
+		for i := max(bpos, 0); i < min(epos, len(line)); i++ {
+			styles[i] += style
+		}
+	}
+
+	return renderStyles(line, styles)
+}
+
+// regionStyle returns the style of a selected region. Except for matching parens,
+// regions with a background use the active region color if any, or reverse video.
+func regionStyle(region core.Selection, activeRegion string) string {
+	fg, bg := region.Highlights()
 
-	if commentsMatch, err := regexp.Compile(commentPattern); err == nil {
-		commentColor := color.SGRStart + color.Fg + "244" + color.SGREnd
-		highlighted = commentsMatch.ReplaceAllString(highlighted, fmt.Sprintf("%s${0}%s", commentColor, color.Reset))
+	if bg != "" && region.Type != "matcher" {
+		if bg = color.UnquoteRC(activeRegion); bg == "" {
+			bg = color.Reverse
+		}
 	}
 
-	highlighted += color.Reset
+	return bg + fg
+}
+
+// runeMatches returns the rune indexes of all matches of a regular expression in a line.
+func runeMatches(regex *regexp.Regexp, line []rune) [][2]int {
+	str := string(line)
 
-	return highlighted
+	var matches [][2]int
+
+	for _, match := range regex.FindAllStringIndex(str, -1) {
+		start := utf8.RuneCountInString(str[:match[0]])
+		end := start + utf8.RuneCountInString(str[match[0]:match[1]])
+
+		matches = append(matches, [2]int{start, end})
+	}
+
+	return matches
 }
 
 func sortHighlights(vhl core.Selection) []core.Selection {
@@ -98,134 +136,3 @@ func sortHighlights(vhl core.Selection) []core.Selection {
 
 	return sorted
 }
-
-func (e *Engine) getHighlights(line []rune, sorted []core.Selection) map[int][]rune {
-	highlights := make(map[int][]rune)
-
-	// Find any highlighting already applied on the line,
-	// and keep the indexes so that we can skip those.
-	var colors [][]int
-
-	colorMatch := regexp.MustCompile(`\x1b\[[0-9;]+m`)
-	colors = colorMatch.FindAllStringIndex(string(line), -1)
-
-	// marks that started highlighting, but not done yet.
-	regions := make([]core.Selection, 0)
-	pos := -1
-	skip := 0
-
-	// Build the string.
-	for rawIndex := range line {
-		var posHl []rune
-		var newHl core.Selection
-
-		// While in a color escape, keep reading runes.
-		if skip > 0 {
-			skip--
-			continue
-		}
-
-		// If starting a color escape code, add offset and read.
-		if len(colors) > 0 && colors[0][0] == rawIndex {
-			skip += colors[0][1] - colors[0][0] - 1
-			colors = colors[1:]
-
-			continue
-		}
-
-		// Or we are reading a printed rune.
-		pos++
-
-		// First check if we have a new highlighter to apply
-		for _, hl := range sorted {
-			bpos, _ := hl.Pos()
-
-			if bpos == pos {
-				newHl = hl
-				regions = append(regions, hl)
-			}
-		}
-
-		// Add new colors if any, and reset if some are done.
-		regions, posHl = e.hlReset(regions, posHl, pos)
-		posHl = e.hlAdd(regions, newHl, posHl)
-
-		// Add to the line, with the raw index since
-		// we must take into account embedded colors.
-		if len(posHl) > 0 {
-			highlights[rawIndex] = posHl
-		}
-	}
-
-	return highlights
-}
-
-func (e *Engine) hlAdd(regions []core.Selection, newHl core.Selection, line []rune) []rune {
-	var (
-		fg, bg  string
-		matcher bool
-		hl      core.Selection
-	)
-
-	if newHl.Active() {
-		hl = newHl
-	} else if len(regions) > 0 {
-		hl = regions[len(regions)-1]
-	}
-
-	fg, bg = hl.Highlights()
-	matcher = hl.Type == "matcher"
-
-	// Update the highlighting with inputrc settings if any.
-	if bg != "" && !matcher {
-		background := color.UnquoteRC("active-region-start-color")
-		if bg, _ = strconv.Unquote(background); bg == "" {
-			bg = color.Reverse
-		}
-	}
-
-	// Add highlightings
-	line = append(line, []rune(bg)...)
-	line = append(line, []rune(fg)...)
-
-	return line
-}
-
-func (e *Engine) hlReset(regions []core.Selection, line []rune, pos int) ([]core.Selection, []rune) {
-	for i, reg := range regions {
-		_, epos := reg.Pos()
-		foreground, background := reg.Highlights()
-		// matcher := reg.Type == "matcher"
-
-		if epos != pos {
-			continue
-		}
-
-		if i < len(regions)-1 {
-			regions = append(regions[:i], regions[i+1:]...)
-		} else {
-			regions = regions[:i]
-		}
-
-		if foreground != "" {
-			line = append(line, []rune(color.FgDefault)...)
-		}
-
-		if background != "" {
-			// background, _ := strconv.Unquote(e.opts.GetString("active-region-end-color"))
-			// foreground := e.opts.GetString("active-region-start-color")
-			line = append(line, []rune(color.ReverseReset)...)
-			line = append(line, []rune(color.BgDefault)...)
-			//	if background == "" && foreground == "" && !matcher {
-			//		line = append(line, []rune(color.ReverseReset)...)
-			//	} else {
-			//
-			//		line = append(line, []rune(color.BgDefault)...)
-			//	}
-			//
-			// line = append(line, []rune(color.ReverseReset)...)
-		}
-	}
-
-	return regions, line
-}
@@ -0,0 +1,38 @@
+package display
+
+import (
+	"github.com/reeflective/readline/internal/core"
+	"github.com/reeflective/readline/internal/term"
+)
+
+// LinePosAt returns the position in the input line displayed at the given terminal
+// coordinates (starting at 1, as reported in mouse events), or false if those are
+// not in the input area or if the position of the input area is not known.
+func (e *Engine) LinePosAt(x, y int) (pos int, found bool) {
+	row := y - e.topRow()
+	if e.startRows < 1 || row < 0 || row > e.lineRows {
+		return 0, false
+	}
+
+	return core.PositionAt(e.cursor, e.startCols, e.horizontalScroll(), x-1, row), true
+}
+
+// CompletionRowAt returns the completion row displayed at the given
+// terminal row (starting at 1), or false if there is no such row.
+func (e *Engine) CompletionRowAt(y int) (row int, found bool) {
+	row = y - e.topRow() - e.lineRows - 1 - e.hintRows
+	if e.startRows < 1 || row < 0 || row > e.compRows {
+		return 0, false
+	}
+
+	return row, true
+}
+
+// topRow returns the terminal row of the first input line, accounting for the
+// screen scrolled when helpers printed below the line overflow the terminal.
+func (e *Engine) topRow() int {
+	bottom := e.startRows + e.lineRows + e.hintRows + e.compRows
+	overflow := bottom - term.GetLength()
+
+	return e.startRows - max(0, overflow)
+}
@@ -3,10 +3,10 @@ package display
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/reeflective/readline/internal/color"
 	"github.com/reeflective/readline/internal/completion"
-	"github.com/reeflective/readline/internal/core"
 	"github.com/reeflective/readline/internal/strutil"
 	"github.com/reeflective/readline/internal/term"
 	"github.com/reeflective/readline/internal/ui"
@@ -14,135 +14,215 @@ import (
 
 // Refresh recomputes and redisplays the entire readline interface, except
 // the first lines of the primary prompt when the latter is a multiline one.
+// Only the terminal rows that changed since the last refresh are printed,
+// in a single synchronized update when the terminal supports it.
 func (e *Engine) Refresh() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.refresh()
+}
+
+// RefreshToolbar refreshes the interface like RefreshBackground if there is a status line.
+func (e *Engine) RefreshToolbar() {
+	if !e.prompt.HasToolbar() {
+		return
+	}
+
+	e.RefreshBackground()
+}
+
+// RefreshBackground refreshes the interface like Refresh, from another goroutine,
+// unless everything needs to be redrawn (after a resize, or when something else has been printed): in
+// this case the terminal would be queried for the cursor position, and the reply
+// might never be read if the shell is not reading its input at the same time.
+func (e *Engine) RefreshBackground() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.frame == nil || e.frame.width != term.GetWidth() || e.primaryPrinted {
+		return
+	}
+
+	e.refresh()
+}
+
+func (e *Engine) refresh() {
+	width := term.GetWidth()
+	previous := e.frame
+
+	// Redraw everything if the terminal has been resized,
+	// or if anything else has been printed since last time.
+	if previous != nil && (previous.width != width || e.primaryPrinted) {
+		previous = nil
+	}
+
+	var out strings.Builder
+
 	// 1. Preparation & Coordinates
-	fmt.Print(term.HideCursor)
+	if term.Caps.SyncOutput {
+		out.WriteString(term.SyncUpdateStart)
+	}
+
+	out.WriteString(term.HideCursor)
+
 	// Go back to the first column, and if the primary prompt
 	// was not printed yet, back up to the line's beginning row.
-	term.MoveCursorBackwards(term.GetWidth())
+	out.WriteString("\r")
 
 	if !e.primaryPrinted {
-		term.MoveCursorUp(e.cursorRow)
+		moveRows(&out, e.cursorRow, 0, 0)
 	}
-	// 2. Primary Prompt
-	e.prompt.LastPrint()
-	// Compute Coordinates: StartPos, LineHeight, CursorPos (row/col).
-	e.computeCoordinates(true)
-
-	// Ensure that the indicator is printed if the prompt is empty,
-	// and that we have enough space to print the line.
-	e.ensureIndicatorSpace()
-
-	// Recompute coordinates with the new indentation/cursor position.
-	if e.line.Lines() > 0 {
-		e.cursorCol, e.cursorRow = core.CoordinatesCursor(e.cursor, e.startCols)
-		e.lineCol, e.lineRows = core.CoordinatesLine(e.line, e.startCols)
+
+	e.updateLine()
+
+	// Get the terminal row of the line's beginning when redrawing
+	// everything, otherwise we know it from the previous refresh.
+	if previous == nil {
+		fmt.Print(out.String())
+		out.Reset()
+
+		_, e.startRows = e.keys.GetCursorPos()
 	}
 
-	// Ensure that we have enough space to print the line.
-	// We probe the terminal to verify that we are not at the bottom of the screen.
-	// If we are, we scroll the screen to make space for the line.
-	e.ensureInputSpace()
+	// 2. Primary Prompt & Input Area
+	prompt := e.prompt.RenderLast()
+	e.startCols = strutil.RealLength(prompt)
+	prompt += e.renderFirstIndicator()
+
+	e.computeLineCoordinates(true)
+
+	rows := e.renderInputArea(prompt, width)
 
-	// 3. Input Area Rendering
-	e.renderInputArea()
+	// 3. Helpers & Status line
+	toolbar := e.renderToolbar(width)
 
-	// 4. Helpers Rendering
-	// We clear everything below the input area to ensure that no artifacts
-	// from previous renders (like longer lines or helpers) remain visible.
-	term.MoveCursorDown(1)
-	term.MoveCursorBackwards(term.GetWidth())
-	fmt.Print(term.ClearScreenBelow)
-	term.MoveCursorUp(1)
-	term.MoveCursorForwards(e.lineCol)
+	rows = append(rows, e.renderHelpers(width)...)
+	rows = append(rows, e.toolbarPadding(len(rows))...)
+	rows = append(rows, toolbar...)
 
-	e.renderHelpers()
+	// 4. Print the rows that changed, and keep them for next time.
+	row := drawRows(&out, rows, previous, width)
+
+	e.frame = &frame{rows: rows, width: width}
+	e.primaryPrinted = false
+
+	// If the frame has scrolled the terminal, the line has moved up.
+	if e.startRows > 0 {
+		e.startRows -= max(0, e.startRows+len(rows)-1-term.GetLength())
+	}
 
 	// 5. Final Cursor Positioning
-	// The cursor is currently at the end of the input line (lineRows, lineCol).
-	// We need to move it to the actual cursor position (cursorRow, cursorCol).
-	if e.lineRows > e.cursorRow {
-		term.MoveCursorUp(e.lineRows - e.cursorRow)
+	moveRows(&out, row, e.cursorRow, len(rows))
+	out.WriteString("\r")
+
+	if e.cursorCol > 0 {
+		fmt.Fprintf(&out, "\x1b[%dC", e.cursorCol)
 	}
 
-	term.MoveCursorBackwards(term.GetWidth())
-	term.MoveCursorForwards(e.cursorCol)
+	out.WriteString(term.ShowCursor)
+
+	if term.Caps.SyncOutput {
+		out.WriteString(term.SyncUpdateEnd)
+	}
 
-	fmt.Print(term.ShowCursor)
+	fmt.Print(out.String())
 }
 
-func (e *Engine) renderInputArea() {
-	e.displayLineRefactored()
-	e.renderMultilineIndicators()
-	e.renderRightPrompt()
+// renderInputArea returns the rows of the input line, starting with the last line of
+// the primary prompt, with multiline indicators and the right prompt if there is room.
+func (e *Engine) renderInputArea(prompt string, width int) []string {
+	line := e.renderLine()
+
+	if scroll := e.horizontalScroll(); scroll.Scrolls(e.line) {
+		line = scroll.Window(line, e.startCols)
+	}
+
+	rows := splitRows(prompt+line, width, e.renderIndicator)
+
+	// A line using all columns of its last row leaves the cursor on the next one.
+	for len(rows) <= e.lineRows {
+		rows = append(rows, "")
+	}
+
+	rows[e.lineRows] += e.prompt.RenderRight(e.lineCol, true)
+
+	return rows
 }
 
-func (e *Engine) renderHelpers() {
+// renderHelpers returns the rows of the hint and completions, if any.
+func (e *Engine) renderHelpers(width int) (rows []string) {
 	e.completer.Autocomplete()
 
-	// 1. Check if we have anything to print.
-	hintRows := ui.CoordinatesHint(e.hint)
 	compMatches := e.completer.Matches()
 	compSkip := e.completer.DisplaySkipped()
 
-	// 2. Clear below the input line to remove artifacts,
-	// unless we are at the bottom of the screen.
-	termHeight := term.GetLength()
-	if (e.startRows + e.lineRows) < termHeight {
-		term.MoveCursorDown(1)
-		term.MoveCursorBackwards(term.GetWidth())
-		fmt.Print(term.ClearScreenBelow)
-		term.MoveCursorUp(1)
-		term.MoveCursorForwards(e.lineCol)
-	}
+	// 1. Hints
+	e.hintRows = 0
 
-	if hintRows == 0 && (compMatches == 0 || compSkip) {
-		e.hintRows = 0
-		e.compRows = 0
+	hint := ui.RenderHint(e.hint)
 
-		return
+	// Without another hint, show the diagnostic under the cursor.
+	if hint == "" {
+		hint = e.renderDiagnostic()
 	}
 
-	fmt.Print(term.NewlineReturn)
-
-	// 3. Display Hints
-	ui.DisplayHint(e.hint)
-	e.hintRows = ui.CoordinatesHint(e.hint)
+	if hint != "" {
+		rows = splitRows(strings.TrimSuffix(hint, term.NewlineReturn), width, nil)
+		e.hintRows = len(rows)
+	}
 
-	// 4. Display Completions
+	// 2. Completions
 	if compMatches > 0 && !compSkip {
-		completion.Display(e.completer, e.AvailableHelperLines())
+		comps := completion.Render(e.completer, e.AvailableHelperLines())
+		rows = append(rows, splitRows(comps, width, nil)...)
 		e.compRows = completion.Coordinates(e.completer)
 	} else {
 		e.completer.ResetUsedRows()
 		e.compRows = 0
 	}
 
-	// 5. Restore Cursor to the "bottom of input area"
-	// The cursor is currently at the bottom of the helpers.
-	// We need to move it back up to the line just below the input text.
-	term.MoveCursorUp(e.compRows)
-	term.MoveCursorUp(e.hintRows)
-	term.MoveCursorUp(1)
+	return rows
+}
+
+// renderToolbar returns the rows of the status line, if any.
+func (e *Engine) renderToolbar(width int) (rows []string) {
+	e.toolbarRows = 0
 
-	// We are now on the same row as the end of the input line,
-	// but at column 0. We need to move to e.lineCol.
-	term.MoveCursorForwards(e.lineCol)
+	toolbar := e.prompt.RenderToolbar()
+	if toolbar == "" {
+		return nil
+	}
+
+	rows = splitRows(strings.TrimSuffix(toolbar, "\n"), width, nil)
+	e.toolbarRows = len(rows)
+
+	return rows
 }
 
-func (e *Engine) renderRightPrompt() {
-	e.prompt.RightPrint(e.lineCol, true)
+// toolbarPadding returns the empty rows needed between the helpers and the status
+// line so that the latter is printed on the last terminal rows, when configured so
+// and if we know on which terminal row the input area starts.
+func (e *Engine) toolbarPadding(usedRows int) []string {
+	if e.toolbarRows == 0 || e.startRows < 1 || !e.opts.GetBool("toolbar-at-bottom") {
+		return nil
+	}
 
-	// Restore cursor to the end of the input line.
-	term.MoveCursorBackwards(term.GetWidth())
-	term.MoveCursorForwards(e.lineCol)
+	padding := term.GetLength() - (e.startRows - 1) - usedRows - e.toolbarRows
+
+	return make([]string, max(0, padding))
 }
 
-func (e *Engine) ensureIndicatorSpace() {
+// renderFirstIndicator returns the multiline indicator to print on the first line
+// when the prompt is empty, and ensures that the indentation of the input line is at
+// least as wide as the indicator, otherwise the indicator would overwrite the text on
+// subsequent lines.
+func (e *Engine) renderFirstIndicator() (indicator string) {
+	if e.line.Lines() == 0 {
+		return ""
+	}
+
 	// Determine the width of the multiline indicator.
-	// We need to ensure that the indentation of the input line is at least
-	// as wide as the indicator, otherwise the indicator will overwrite the text
-	// on subsequent lines.
 	var indicatorWidth int
 	if e.opts.GetBool("multiline-column-numbered") {
 		indicatorWidth = len(strconv.Itoa(1)) + 1
@@ -152,8 +232,7 @@ func (e *Engine) ensureIndicatorSpace() {
 
 	// Adjust indentation if the primary prompt is empty,
 	// because we will print a column indicator on the first line.
-	if e.prompt.LastUsed() == 0 && e.line.Lines() > 0 {
-		var indicator string
+	if e.startCols == 0 {
 		if e.opts.GetBool("multiline-column-numbered") {
 			indicator = fmt.Sprintf(color.FgBlackBright+"%d"+color.Reset+" ", 1)
 		} else {
@@ -161,129 +240,41 @@ func (e *Engine) ensureIndicatorSpace() {
 		}
 
 		e.startCols += indicatorWidth
-		// Print the indicator on the first line.
-		fmt.Print(indicator)
-	} else if e.line.Lines() > 0 && e.startCols < indicatorWidth {
-		// If the prompt is shorter than the indicator, pad with spaces
-		// to ensure the input text starts aligned with subsequent lines
-		// and isn't overwritten by the indicator.
-		padding := indicatorWidth - e.startCols
-		fmt.Print(fmt.Sprintf("%*s", padding, ""))
-
-		e.startCols = indicatorWidth
-	}
-}
 
-func (e *Engine) ensureInputSpace() {
-	if e.lineRows <= 1 {
-		return
+		return indicator
 	}
 
-	// 1. Probe the terminal height.
-	// We move the cursor down to the last line of the input line,
-	// and check if the cursor is at the expected position.
-	term.MoveCursorDown(e.lineRows - 1)
-	_, actualRow := e.keys.GetCursorPos()
-	term.MoveCursorUp(e.lineRows - 1)
-
-	// 2. Calculate the overshoot.
-	expectedRow := e.startRows + e.lineRows - 1
-	overshoot := expectedRow - actualRow
-
-	// 3. Scroll the screen if needed.
-	if overshoot > 0 {
-		// Move to the bottom of the terminal.
-		term.MoveCursorDown(actualRow - e.startRows)
-
-		// Scroll the screen by printing newlines.
-		for range overshoot {
-			fmt.Print("\n")
-		}
-
-		// Update the start row to reflect the scrolling.
-		e.startRows -= overshoot
-
-		// Move the cursor back up to the new start position.
-		term.MoveCursorUp(e.lineRows - 1)
-		term.MoveCursorForwards(e.startCols)
+	// If the prompt is shorter than the indicator, pad with spaces
+	// to ensure the input text starts aligned with subsequent lines.
+	if e.startCols < indicatorWidth {
+		indicator = strings.Repeat(" ", indicatorWidth-e.startCols)
+		e.startCols = indicatorWidth
 	}
-}
 
-func (e *Engine) displayLineRefactored() {
-	var line string
-	// Apply user-defined highlighter to the input line.
-	if e.highlighter != nil {
-		line = e.highlighter(*e.line)
-	} else {
-		line = string(*e.line)
-	}
-	// Highlight matching parenthesis
-	if e.opts.GetBool("blink-matching-paren") {
-		core.HighlightMatchers(e.selection)
-		defer core.ResetMatchers(e.selection)
-	}
-	// Apply visual selections highlighting if any
-	line = e.highlightLine([]rune(line), *e.selection)
-	// Get the subset of the suggested line to print.
-	if len(e.suggested) > e.line.Len() && e.opts.GetBool("history-autosuggest") {
-		line += color.Dim + color.Fmt(color.Fg+"242") + string(e.suggested[e.line.Len():]) + color.Reset
-	}
-	// Format tabs as spaces, for consistent display
-	line = strutil.FormatTabs(line) + term.ClearLineAfter
-	// And display the line.
-	e.suggested.Set([]rune(line)...)
-	core.DisplayLine(&e.suggested, e.startCols)
+	return indicator
 }
 
-func (e *Engine) renderMultilineIndicators() {
-	// Check if we have multiple lines to manage.
-	if e.line.Lines() == 0 {
-		return
-	}
-
-	// 1. Determine if we need to print columns.
+// renderIndicator returns the multiline indicator starting a line (other than the
+// first) of the input buffer, padded so that all lines are aligned on the first one.
+func (e *Engine) renderIndicator(line int) string {
 	columns := e.opts.GetBool("multiline-column") ||
 		e.opts.GetBool("multiline-column-numbered") ||
 		e.opts.GetString("multiline-column-custom") != ""
 	promptEmpty := e.prompt.LastUsed() == 0
 
-	// If no columns are requested and the prompt is not empty, we have nothing to do.
-	if !columns && !promptEmpty {
-		return
-	}
-
-	// 2. Move to the top of the input area (first line).
-	term.MoveCursorUp(e.lineRows)
-	term.MoveCursorBackwards(term.GetWidth())
-
-	// 3. Print the indicators for subsequent lines (1..N).
-	printedLines := 0
-	numbered := e.opts.GetBool("multiline-column-numbered")
-
-	// Indicators
-	pipe := ui.DefaultMultilineColumn
+	var indicator string
 
-	for i := 1; i <= e.line.Lines(); i++ {
-		fmt.Print("\n")
-
-		if numbered {
-			fmt.Print(fmt.Sprintf(color.FgBlackBright+"%d"+color.Reset+" ", i+1))
-		} else if i == e.line.Lines() {
-			e.prompt.SecondaryPrint()
-		} else {
-			fmt.Print(pipe)
-		}
-
-		printedLines++
+	switch {
+	case !columns && !promptEmpty:
+	case e.opts.GetBool("multiline-column-numbered"):
+		indicator = fmt.Sprintf(color.FgBlackBright+"%d"+color.Reset+" ", line+1)
+	case line == e.line.Lines():
+		indicator = e.prompt.RenderSecondary()
+	default:
+		indicator = ui.DefaultMultilineColumn
 	}
 
-	// 4. Return cursor to the bottom of the input area.
-	correction := e.lineRows - printedLines
-	if correction > 0 {
-		term.MoveCursorDown(correction)
-	}
+	padding := max(0, e.startCols-strutil.RealLength(indicator))
 
-	// 5. Restore horizontal position to the end of the input text.
-	term.MoveCursorBackwards(term.GetWidth())
-	term.MoveCursorForwards(e.lineCol)
+	return indicator + strings.Repeat(" ", padding)
 }
@@ -0,0 +1,115 @@
+package display
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/reeflective/readline/internal/color"
+)
+
+// spanCacheSize is the maximum number of lines for which highlighted spans are kept.
+var spanCacheSize = 128
+
+// Span is a range of runes in the input line, from Start (included) to End (excluded),
+// highlighted with a style made of one or more SGR sequences (colors and effects).
+type Span struct {
+	Start int
+	End   int
+	Style string
+}
+
+// syntaxStyles returns the style of each rune of the line, as given by the span
+// highlighter if any, or as parsed from the line highlighted by the legacy one.
+func (e *Engine) syntaxStyles(line []rune) []string {
+	switch {
+	case e.spanHighlighter != nil:
+		return spanStyles(e.highlightSpans(line), len(line))
+	case e.highlighter != nil:
+		return ansiStyles(e.highlighter(line), len(line))
+	default:
+		return make([]string, len(line))
+	}
+}
+
+// highlightSpans returns the spans of the line from the cache, or from the span
+// highlighter if the line has not been highlighted yet. The cache is emptied when
+// it is full, since lines are mostly edited one rune after another.
+func (e *Engine) highlightSpans(line []rune) []Span {
+	if spans, found := e.spans[string(line)]; found {
+		return spans
+	}
+
+	if e.spans == nil || len(e.spans) >= spanCacheSize {
+		e.spans = make(map[string][]Span)
+	}
+
+	spans := e.spanHighlighter(line)
+	e.spans[string(line)] = spans
+
+	return spans
+}
+
+// spanStyles returns the style of each rune of a line of the given length.
+// Spans are applied in order, so that later ones override previous ones.
+func spanStyles(spans []Span, length int) []string {
+	styles := make([]string, length)
+
+	for _, span := range spans {
+		style := color.Downgrade(span.Style)
+
+		for i := max(span.Start, 0); i < min(span.End, length); i++ {
+			styles[i] = style
+		}
+	}
+
+	return styles
+}
+
+// ansiStyles returns the style of each rune of a line of the given length, as
+// the SGR sequences active before each printable rune of its highlighted string.
+func ansiStyles(highlighted string, length int) []string {
+	styles := make([]string, length)
+
+	highlighted = color.Downgrade(highlighted)
+
+	var effects string
+	var pos int
+
+	for len(highlighted) > 0 && pos < length {
+		if seq := rxEscape.FindString(highlighted); seq != "" {
+			highlighted = highlighted[len(seq):]
+
+			effects = addEffects(effects, seq)
+
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(highlighted)
+		highlighted = highlighted[size:]
+
+		styles[pos] = effects
+		pos++
+	}
+
+	return styles
+}
+
+// renderStyles returns the line with each rune preceded by its style
+// when the latter differs from the style of the previous rune.
+func renderStyles(line []rune, styles []string) string {
+	var out strings.Builder
+	var previous string
+
+	for i, r := range line {
+		if styles[i] != previous {
+			out.WriteString(color.Reset + styles[i])
+			previous = styles[i]
+		}
+
+		out.WriteRune(r)
+	}
+
+	out.WriteString(color.Reset)
+
+	return out.String()
+}
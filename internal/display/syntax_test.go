@@ -0,0 +1,130 @@
+package display
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/reeflective/readline/internal/term"
+)
+
+func TestSpanStyles(t *testing.T) {
+	defer func(caps term.Capabilities) { term.Caps = caps }(term.Caps)
+	term.Caps.Colors = term.ColorTrue
+
+	tests := []struct {
+		name   string
+		spans  []Span
+		length int
+		want   []string
+	}{
+		{
+			name:   "No spans",
+			length: 3,
+			want:   []string{"", "", ""},
+		},
+		{
+			name:   "Single span",
+			spans:  []Span{{Start: 0, End: 2, Style: "\x1b[31m"}},
+			length: 3,
+			want:   []string{"\x1b[31m", "\x1b[31m", ""},
+		},
+		{
+			name: "Overlapping spans",
+			spans: []Span{
+				{Start: 0, End: 3, Style: "\x1b[31m"},
+				{Start: 1, End: 2, Style: "\x1b[1m"},
+			},
+			length: 3,
+			want:   []string{"\x1b[31m", "\x1b[1m", "\x1b[31m"},
+		},
+		{
+			name:   "Out of range span",
+			spans:  []Span{{Start: -1, End: 10, Style: "\x1b[32m"}},
+			length: 2,
+			want:   []string{"\x1b[32m", "\x1b[32m"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := spanStyles(test.spans, test.length); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("spanStyles() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAnsiStyles(t *testing.T) {
+	defer func(caps term.Capabilities) { term.Caps = caps }(term.Caps)
+	term.Caps.Colors = term.ColorTrue
+
+	tests := []struct {
+		name        string
+		highlighted string
+		length      int
+		want        []string
+	}{
+		{
+			name:        "Plain line",
+			highlighted: "ls",
+			length:      2,
+			want:        []string{"", ""},
+		},
+		{
+			name:        "Colored word",
+			highlighted: "\x1b[32mls\x1b[0m -l",
+			length:      5,
+			want:        []string{"\x1b[32m", "\x1b[32m", "", "", ""},
+		},
+		{
+			name:        "Stacked effects",
+			highlighted: "\x1b[1m\x1b[31mé\x1b[0mx",
+			length:      2,
+			want:        []string{"\x1b[1m\x1b[31m", ""},
+		},
+		{
+			name:        "Longer highlighted line",
+			highlighted: "\x1b[31mabc",
+			length:      2,
+			want:        []string{"\x1b[31m", "\x1b[31m"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ansiStyles(test.highlighted, test.length); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ansiStyles() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRenderStyles(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		styles []string
+		want   string
+	}{
+		{
+			name:   "No styles",
+			line:   "ls",
+			styles: []string{"", ""},
+			want:   "ls\x1b[0m",
+		},
+		{
+			name:   "Merged runs",
+			line:   "ls -l",
+			styles: []string{"\x1b[32m", "\x1b[32m", "", "\x1b[7m", "\x1b[7m"},
+			want:   "\x1b[0m\x1b[32mls\x1b[0m \x1b[0m\x1b[7m-l\x1b[0m",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := renderStyles([]rune(test.line), test.styles); got != test.want {
+				t.Errorf("renderStyles() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
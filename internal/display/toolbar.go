@@ -0,0 +1,31 @@
+package display
+
+import "time"
+
+// WatchToolbar periodically refreshes the interface while the shell is waiting for
+// input keys and has a status line, so that the latter is re-evaluated: the shell
+// does not run any command until the refresh is done. Does nothing if the interval
+// (in milliseconds) is not positive.
+func WatchToolbar(eng *Engine, interval int) chan<- bool {
+	done := make(chan bool, 1)
+
+	if interval <= 0 {
+		return done
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				eng.keys.WhileWaiting(eng.RefreshToolbar)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return done
+}
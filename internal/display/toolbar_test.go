@@ -0,0 +1,89 @@
+package display
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/reeflective/readline/inputrc"
+	"github.com/reeflective/readline/internal/term"
+	"github.com/reeflective/readline/internal/ui"
+)
+
+func TestEngineRenderToolbar(t *testing.T) {
+	tests := []struct {
+		name    string
+		toolbar func() string
+		width   int
+		want    []string
+	}{
+		{
+			name:  "No status line",
+			width: 10,
+		},
+		{
+			name:    "Status line",
+			toolbar: func() string { return "main\n" },
+			width:   10,
+			want:    []string{"main"},
+		},
+		{
+			name:    "Wrapped status line",
+			toolbar: func() string { return "main | 2 jobs" },
+			width:   8,
+			want:    []string{"main | 2", " jobs"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prompt := ui.NewPrompt(nil, nil, nil, inputrc.NewDefaultConfig())
+			if test.toolbar != nil {
+				prompt.Toolbar(test.toolbar)
+			}
+
+			eng := &Engine{prompt: prompt, toolbarRows: 3}
+
+			rows := eng.renderToolbar(test.width)
+			if !slices.Equal(rows, test.want) {
+				t.Errorf("renderToolbar() = %q, want %q", rows, test.want)
+			}
+
+			if eng.toolbarRows != len(test.want) {
+				t.Errorf("renderToolbar() toolbar rows = %d, want %d", eng.toolbarRows, len(test.want))
+			}
+		})
+	}
+}
+
+func TestEngineAvailableHelperLinesToolbar(t *testing.T) {
+	if height := term.GetLength(); height-4 < height/oneThirdTerminalHeight {
+		t.Skip("terminal too small to reserve rows for the status line")
+	}
+
+	eng := &Engine{startRows: 1, lineRows: 1}
+	available := eng.AvailableHelperLines()
+
+	eng.toolbarRows = 2
+
+	if got, want := eng.AvailableHelperLines(), available-2; got != want {
+		t.Errorf("AvailableHelperLines() with a status line = %d, want %d", got, want)
+	}
+}
+
+func TestEngineRefreshToolbar(t *testing.T) {
+	prompt := ui.NewPrompt(nil, nil, nil, inputrc.NewDefaultConfig())
+	prompt.Toolbar(func() string { return "main" })
+
+	// Without a previous frame, refreshing would query the cursor
+	// position, which keys are nil here: the refresh must be skipped.
+	eng := &Engine{prompt: prompt}
+	eng.RefreshToolbar()
+
+	eng.primaryPrinted = true
+	eng.frame = &frame{width: term.GetWidth()}
+	eng.RefreshToolbar()
+
+	if eng.frame.rows != nil {
+		t.Errorf("RefreshToolbar() redrew the interface, want it skipped")
+	}
+}
@@ -19,6 +19,7 @@ type Sources struct {
 	line   *core.Line
 	cursor *core.Cursor
 	hint   *ui.Hint
+	bell   *ui.Bell
 	config *inputrc.Config
 
 	// History sources
@@ -44,7 +45,7 @@ type Sources struct {
 }
 
 // NewSources is a required constructor for the history sources manager type.
-func NewSources(line *core.Line, cur *core.Cursor, hint *ui.Hint, opts *inputrc.Config) *Sources {
+func NewSources(line *core.Line, cur *core.Cursor, hint *ui.Hint, bell *ui.Bell, opts *inputrc.Config) *Sources {
 	sources := &Sources{
 		// History sources
 		list: make(map[string]Source),
@@ -56,6 +57,7 @@ func NewSources(line *core.Line, cur *core.Cursor, hint *ui.Hint, opts *inputrc.
 		cpos:   -1,
 		hpos:   -1,
 		hint:   hint,
+		bell:   bell,
 		config: opts,
 	}
 
@@ -397,6 +399,8 @@ func (h *Sources) InsertMatch(line *core.Line, cur *core.Cursor, usePos, fwd, re
 	// If no match was found, return anyway, but if we were going forward
 	// (down to the current input line), reinstore the main line buffer.
 	if !found {
+		h.bell.Ring()
+
 		if fwd {
 			h.hpos = -1
 			h.Undo()
@@ -14,12 +14,18 @@ var menuselectKeys = map[string]inputrc.Bind{
 	unescape(`\e[Z`):    {Action: "menu-complete-backward"},
 	unescape(`\C-@`):    {Action: "accept-and-menu-complete"},
 	unescape(`\C-F`):    {Action: "menu-incremental-search"},
+	unescape(`\C-T`):    {Action: "menu-toggle-mark"},
 	unescape(`\e[A`):    {Action: "menu-complete-backward"},
 	unescape(`\e[B`):    {Action: "menu-complete"},
 	unescape(`\e[C`):    {Action: "menu-complete"},
 	unescape(`\e[D`):    {Action: "menu-complete-backward"},
 	unescape(`\e[1;5A`): {Action: "menu-complete-prev-tag"},
 	unescape(`\e[1;5B`): {Action: "menu-complete-next-tag"},
+	unescape(`\e[5~`):   {Action: "menu-preview-scroll-up"},
+	unescape(`\e[6~`):   {Action: "menu-preview-scroll-down"},
+	unescape(`\e[<0M`):  {Action: "mouse-click"},
+	unescape(`\e[<64M`): {Action: "mouse-scroll-up"},
+	unescape(`\e[<65M`): {Action: "mouse-scroll-down"},
 }
 
 // isearchCommands is a subset of commands that are valid in incremental-search mode.
@@ -13,12 +13,31 @@ import (
 // readline global options specific to this library.
 var readlineOptions = map[string]interface{}{
 	// General edition
-	"autopairs": false,
+	"autopairs":            false,
+	"enable-mouse":         false,
+	"enable-extended-keys": false,
+	"query-terminal":       false,
 
 	// Completion
-	"autocomplete":               false,
-	"completion-list-separator":  "--",
-	"completion-selection-style": "\x1b[1;30m",
+	"autocomplete":                 false,
+	"complete-common-prefix":       false,
+	"completion-list-separator":    "--",
+	"completion-selection-style":   "\x1b[1;30m",
+	"completion-marked-style":      "\x1b[4m",
+	"completion-preview-lines":     10,
+	"completion-preview-position":  "below",
+	"completion-description-lines": 1,
+	"completion-frecency-file":     "",
+	"completion-error-style":       "\x1b[31m",
+	"completion-async-error-style": "\x1b[2;31m",
+	"completion-warning-style":     "\x1b[33m",
+	"completion-info-style":        "\x1b[2m",
+	"completion-usage-style":       "\x1b[2m",
+
+	// Validation
+	"diagnostic-error-style":   "\x1b[31m",
+	"diagnostic-warning-style": "\x1b[33m",
+	"diagnostic-info-style":    "\x1b[2m",
 
 	// Prompt & General UI
 	"transient-prompt":          false,
@@ -26,6 +45,8 @@ var readlineOptions = map[string]interface{}{
 	"history-autosuggest":       false,
 	"multiline-column":          true,
 	"multiline-column-numbered": false,
+	"toolbar-at-bottom":         true,
+	"toolbar-refresh-interval":  0,
 }
 
 // ReloadConfig parses all valid .inputrc configurations and immediately
@@ -108,6 +129,13 @@ func (m *Engine) loadBuiltinBinds() {
 		m.config.Binds[string(ViInsert)][seq] = bind
 	}
 
+	// Mouse events
+	for _, keymap := range []Mode{Emacs, ViInsert, ViCommand, ViMove, Vi} {
+		for seq, bind := range mouseKeys {
+			m.config.Binds[string(keymap)][seq] = bind
+		}
+	}
+
 	// Vim local keymaps
 	m.config.Binds[string(Visual)] = visualKeys
 	m.config.Binds[string(ViOpp)] = vioppKeys
@@ -3,6 +3,8 @@ package keymap
 import (
 	"fmt"
 	"strings"
+
+	"github.com/reeflective/readline/internal/term"
 )
 
 // CursorStyle is the style of the cursor
@@ -10,7 +12,12 @@ import (
 type CursorStyle string
 
 // String - Implements fmt.Stringer.
+// Returns nothing if the terminal cannot change the cursor shape.
 func (c CursorStyle) String() string {
+	if !term.Caps.CursorShape {
+		return ""
+	}
+
 	cursor, found := cursors[c]
 	if !found {
 		return string(cursorUserDefault)
@@ -53,6 +60,10 @@ var defaultCursors = map[Mode]CursorStyle{
 func (m *Engine) PrintCursor(keymap Mode) {
 	var cursor CursorStyle
 
+	if !term.Caps.CursorShape {
+		return
+	}
+
 	// Check for a configured cursor in .inputrc file.
 	cursorOptname := "cursor-" + string(keymap)
 	modeSet := strings.TrimSpace(m.config.GetString(cursorOptname))
@@ -35,6 +35,12 @@ func MatchLocal(eng *Engine) (bind inputrc.Bind, command func(), prefix bool) {
 		core.MatchedKeys(eng.keys, matched, read[len(matched):]...)
 	}
 
+	// Keys that are meaningful on their own but also match longer
+	// binds are resolved when more keys are read, or on timeout.
+	if eng.waitKeyseq(prefix, true) {
+		return bind, nil, true
+	}
+
 	// Similarly to the MatchMain() function, give a special treatment to the escape key
 	// (if it's alone): using escape in Viopp/menu-complete/isearch should cancel the
 	// current mode, thus we return either a Vim movement-mode command, or nothing.
@@ -60,7 +66,7 @@ func MatchMain(eng *Engine) (bind inputrc.Bind, command func(), prefix bool) {
 	}
 
 	// Find the target action, macro or command.
-	bind, prefix, read, _ := eng.dispatchKeys(binds)
+	bind, prefix, read, matched := eng.dispatchKeys(binds)
 
 	if !bind.Macro {
 		command = eng.commands[bind.Action]
@@ -68,13 +74,23 @@ func MatchMain(eng *Engine) (bind inputrc.Bind, command func(), prefix bool) {
 
 	// In the main menu, all keys that have been tested against
 	// the binds will be dropped after command execution (whether
-	// or not there's actually a command to execute).
-	if prefix {
+	// or not there's actually a command to execute), except those
+	// read past a shorter bind used because longer ones did not match.
+	switch {
+	case prefix:
 		core.MatchedPrefix(eng.keys, read...)
-	} else {
+	case bind.Action != "" || bind.Macro:
+		core.MatchedKeys(eng.keys, matched, read[len(matched):]...)
+	default:
 		core.MatchedKeys(eng.keys, read)
 	}
 
+	// Keys that are meaningful on their own but also match longer
+	// binds are resolved when more keys are read, or on timeout.
+	if eng.waitKeyseq(prefix, !eng.IsEmacs()) {
+		return bind, nil, true
+	}
+
 	// Non-incremental search mode should always insert the keys
 	// if they did not exactly match one of the valid commands.
 	if eng.nonIncSearch && (command == nil || prefix) {
@@ -97,6 +113,8 @@ func MatchMain(eng *Engine) (bind inputrc.Bind, command func(), prefix bool) {
 }
 
 func (m *Engine) dispatchKeys(binds map[string]inputrc.Bind) (bind inputrc.Bind, prefix bool, read, matched []byte) {
+	m.ambiguous = false
+
 	// Support for Unicode: if the character is multi-byte (UTF-8), consume all its bytes
 	// and treat it as a single self-insert action. Note that we just peek the characters
 	// here, so if it's actually not a UTF-8 character, we just keep going and re-peek later.
@@ -126,6 +144,12 @@ func (m *Engine) dispatchKeys(binds map[string]inputrc.Bind) (bind inputrc.Bind,
 		// has some implications if the terminal is sending 8-bit characters (extended alphabet).
 		key, empty := core.PeekKey(m.keys)
 		if empty {
+			// The keys matched a bind both exactly and by prefix,
+			// and no key was read before keyseq-timeout: use it.
+			if prefix && m.prefixed.Action != "" && core.KeyseqExpired(m.keys) {
+				prefix = m.makeMatch(m.prefixed, inputrc.Bind{})
+			}
+
 			break
 		}
 
@@ -159,6 +183,7 @@ func (m *Engine) dispatchKeys(binds map[string]inputrc.Bind) (bind inputrc.Bind,
 		// If we matched a prefix, keep the matched bind for later.
 		if len(prefixed) > 0 {
 			prefix = true
+			m.ambiguous = match.Action != ""
 
 			if match.Action != "" {
 				m.prefixed = match
@@ -224,6 +249,24 @@ func (m *Engine) resolve(bind inputrc.Bind) func() {
 	return m.commands[bind.Action]
 }
 
+// waitKeyseq returns true if the keys matched by prefix are meaningful on their own,
+// because they also exactly match a bind or, when escape is true, because they are a
+// lone escape key. In this case, more keys are read until keyseq-timeout expires, after
+// which the shorter bind is used. Keys are always awaited if keyseq-timeout is not set.
+func (m *Engine) waitKeyseq(prefix, escape bool) bool {
+	if !prefix || core.KeyseqExpired(m.keys) || m.config.GetInt("keyseq-timeout") <= 0 {
+		return false
+	}
+
+	if !m.ambiguous && !(escape && m.isEscapeKey()) {
+		return false
+	}
+
+	core.WaitKeyseq(m.keys)
+
+	return true
+}
+
 // handleEscape is used to override or change the matched command when the escape key has
 // been pressed: it might exit completion/isearch menus, use the vi-movement-mode, etc.
 func (m *Engine) handleEscape(main bool) (bind inputrc.Bind, cmd func(), pref bool) {
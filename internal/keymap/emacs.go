@@ -29,4 +29,7 @@ var emacsKeys = map[string]inputrc.Bind{
 	unescape(`\M-u`):     {Action: "up-case-word"},
 	unescape(`\M-w`):     {Action: "kill-region"},
 	unescape(`\M-|`):     {Action: "vi-goto-column"},
+
+	// Extended keys
+	unescape(`\k{S-Return}`): {Action: "newline-insert"},
 }
@@ -12,6 +12,7 @@ type Engine struct {
 	local        Mode
 	main         Mode
 	prefixed     inputrc.Bind
+	ambiguous    bool
 	active       inputrc.Bind
 	pending      []inputrc.Bind
 	skip         bool
@@ -38,6 +39,11 @@ func NewEngine(keys *core.Keys, i *core.Iterations, opts ...inputrc.Option) (*En
 	// Load the inputrc configurations and set up related things.
 	modes.ReloadConfig(opts...)
 
+	// Mouse events are only kept when bound to a command.
+	if keys != nil {
+		keys.SetBindChecker(modes.IsBound)
+	}
+
 	return modes, modes.config
 }
 
@@ -198,6 +204,18 @@ func (m *Engine) ActiveCommand() inputrc.Bind {
 	return m.active
 }
 
+// IsBound returns true if a key sequence is bound to a
+// command in the current local or main keymap.
+func (m *Engine) IsBound(keys string) bool {
+	if _, found := m.getContextBinds(false)[keys]; found {
+		return true
+	}
+
+	_, found := m.getContextBinds(true)[keys]
+
+	return found
+}
+
 // NonIncrementalSearchStart is used to notify the keymap dispatchers
 // that are using a minibuffer, and that the set of valid commands
 // should be restrained to a few ones (self-insert/abort/rubout...).
@@ -0,0 +1,11 @@
+package keymap
+
+import "github.com/reeflective/readline/inputrc"
+
+// mouseKeys are the default mouse binds in all main keymaps, when mouse support is
+// enabled. Mouse events are read as their SGR sequences without coordinates.
+var mouseKeys = map[string]inputrc.Bind{
+	unescape(`\e[<0M`):  {Action: "mouse-click"},
+	unescape(`\e[<64M`): {Action: "mouse-scroll-up"},
+	unescape(`\e[<65M`): {Action: "mouse-scroll-down"},
+}
@@ -25,6 +25,9 @@ var viinsKeys = map[string]inputrc.Bind{
 	unescape(`\M-[A`):  {Action: "up-line-or-search"},
 	unescape(`\M-[B`):  {Action: "down-line-or-search"},
 	unescape(`\M-@`):   {Action: "macro-run"},
+
+	// Extended keys
+	unescape(`\k{S-Return}`): {Action: "newline-insert"},
 }
 
 // viinsKeymaps are the default keymaps in Vim Command mode.
@@ -1,6 +1,7 @@
 package strutil
 
 import (
+	"regexp"
 	"strings"
 
 	"github.com/rivo/uniseg"
@@ -9,6 +10,9 @@ import (
 	"github.com/reeflective/readline/internal/term"
 )
 
+// CSI escape sequences, like those setting colors and effects.
+var rxCSI = regexp.MustCompile(`^\x1b\[[0-9;:?<=>]*[ -/]*[@-~]`)
+
 // FormatTabs replaces all '\t' occurrences in a string with 6 spaces each.
 func FormatTabs(s string) string {
 	return strings.ReplaceAll(s, "\t", "     ")
@@ -41,3 +45,37 @@ func LineSpan(line []rune, idx, indent int) (x, y int) {
 
 	return cursorX, cursorY
 }
+
+// SliceColumns returns the part of a string displayed on the terminal columns from start
+// (included) to end (excluded), keeping all of its escape sequences so that colors are
+// preserved. Wide graphemes only partially displayed in these columns are replaced with
+// spaces.
+func SliceColumns(s string, start, end int) string {
+	var sliced strings.Builder
+	var column int
+
+	s = FormatTabs(s)
+
+	for len(s) > 0 {
+		if seq := rxCSI.FindString(s); seq != "" {
+			sliced.WriteString(seq)
+			s = s[len(seq):]
+
+			continue
+		}
+
+		cluster, rest, width, _ := uniseg.FirstGraphemeClusterInString(s, -1)
+		s = rest
+
+		switch {
+		case column >= start && column+width <= end:
+			sliced.WriteString(cluster)
+		case column < end && column+width > start:
+			sliced.WriteString(strings.Repeat(" ", min(column+width, end)-max(column, start)))
+		}
+
+		column += width
+	}
+
+	return sliced.String()
+}
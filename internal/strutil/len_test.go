@@ -0,0 +1,49 @@
+package strutil
+
+import "testing"
+
+func TestSliceColumns(t *testing.T) {
+	tests := []struct {
+		name       string
+		s          string
+		start, end int
+		want       string
+	}{
+		{
+			name:  "Plain string",
+			s:     "hello world",
+			start: 2,
+			end:   7,
+			want:  "llo w",
+		},
+		{
+			name:  "Colors kept",
+			s:     "\x1b[31mhello\x1b[0m world",
+			start: 3,
+			end:   8,
+			want:  "\x1b[31mlo\x1b[0m wo",
+		},
+		{
+			name:  "Wide graphemes cut",
+			s:     "a日本b",
+			start: 2,
+			end:   4,
+			want:  "  ",
+		},
+		{
+			name:  "Past the end",
+			s:     "ls",
+			start: 1,
+			end:   10,
+			want:  "s",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := SliceColumns(test.s, test.start, test.end); got != test.want {
+				t.Errorf("SliceColumns() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ var (
 	doubleChar        = '"'
 	escapeChar        = '\\'
 	doubleEscapeChars = "$`\"\n\\"
+	quoteChars        = " \n\t'\"\\$`|&;<>()"
 )
 
 // NewlineMatcher is a regular expression matching all newlines or returned newlines.
@@ -72,6 +73,39 @@ func Split(input string) (words []string, err error) {
 	return words, err
 }
 
+// QuoteWord quotes a word so that it is split as a single word by Split.
+// Words that are already quoted, or that do not contain any whitespace
+// or shell special character, are returned unchanged. Single quotes are
+// preferred, unless the word itself contains some.
+func QuoteWord(word string) string {
+	switch {
+	case word == "":
+		return "''"
+	case strings.HasPrefix(word, string(singleChar)), strings.HasPrefix(word, string(doubleChar)):
+		return word
+	case !strings.ContainsAny(word, quoteChars):
+		return word
+	case !strings.ContainsRune(word, singleChar):
+		return string(singleChar) + word + string(singleChar)
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteRune(doubleChar)
+
+	for _, char := range word {
+		if char != '\n' && strings.ContainsRune(doubleEscapeChars, char) {
+			buf.WriteRune(escapeChar)
+		}
+
+		buf.WriteRune(char)
+	}
+
+	buf.WriteRune(doubleChar)
+
+	return buf.String()
+}
+
 func splitWord(input string, buf *bytes.Buffer) (word string, remainder string, err error) {
 	buf.Reset()
 
@@ -0,0 +1,63 @@
+package strutil
+
+import (
+	"testing"
+)
+
+func TestQuoteWord(t *testing.T) {
+	tests := []struct {
+		name string
+		word string
+		want string
+	}{
+		{
+			name: "Empty word",
+			word: "",
+			want: "''",
+		},
+		{
+			name: "Plain word",
+			word: "file.go",
+			want: "file.go",
+		},
+		{
+			name: "Word with spaces",
+			word: "my file.go",
+			want: "'my file.go'",
+		},
+		{
+			name: "Word with single quote",
+			word: "it's here",
+			want: `"it's here"`,
+		},
+		{
+			name: "Word with single quote and double-quote escapes",
+			word: "it's $HOME",
+			want: `"it's \$HOME"`,
+		},
+		{
+			name: "Already quoted word",
+			word: "\"my file.go\"",
+			want: "\"my file.go\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := QuoteWord(tt.word)
+			if got != tt.want {
+				t.Errorf("QuoteWord() = %v, want %v", got, tt.want)
+			}
+
+			// Quoted words must always be split back to the original word.
+			if tt.word == "" || tt.word[0] == '"' {
+				return
+			}
+
+			words, err := Split(got)
+			if err != nil || len(words) != 1 || words[0] != tt.word {
+				t.Errorf("Split(QuoteWord()) = %v (err: %v), want [%v]", words, err, tt.word)
+			}
+		})
+	}
+}
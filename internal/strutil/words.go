@@ -0,0 +1,117 @@
+package strutil
+
+import (
+	"strings"
+)
+
+// Word is a shell word found in a line, with its position and quoting state.
+type Word struct {
+	Value    string // The word with its quotes and escapes removed.
+	Raw      string // The word as found in the line.
+	Start    int    // Position of the first character of the word in the line.
+	End      int    // Position following the last character of the word in the line.
+	Quote    rune   // The quote still open at the end of the word, or the last one used.
+	Unclosed bool   // The word ends within an unclosed quote.
+}
+
+// Tokenize splits a line into shell words, with the same word-splitting rules
+// as Split. Contrary to the latter, it never fails on unterminated quotes or
+// escapes, and returns the raw form and the position of each word in the line.
+func Tokenize(line []rune) []Word {
+	var (
+		words    = make([]Word, 0)
+		word     *Word
+		value    []rune
+		quote    rune
+		escaped  bool
+		inDouble bool // The escape was found in double quotes.
+	)
+
+	for pos, char := range line {
+		if word == nil {
+			if strings.ContainsRune(splitChars, char) {
+				continue
+			}
+
+			word = &Word{Start: pos}
+			value = make([]rune, 0)
+		}
+
+		switch {
+		case escaped:
+			escaped = false
+
+			// Only some characters can be escaped in double quotes.
+			if inDouble && !strings.ContainsRune(doubleEscapeChars, char) {
+				value = append(value, escapeChar)
+			}
+
+			if char != '\n' {
+				value = append(value, char)
+			}
+
+		case char == escapeChar && quote != singleChar:
+			escaped = true
+			inDouble = quote == doubleChar
+
+		case quote != 0 && char == quote:
+			quote = 0
+
+		case quote != 0:
+			value = append(value, char)
+
+		case char == singleChar || char == doubleChar:
+			quote = char
+			word.Quote = char
+
+		case strings.ContainsRune(splitChars, char):
+			word.End = pos
+			word.Raw = string(line[word.Start:pos])
+			word.Value = string(value)
+			words = append(words, *word)
+			word = nil
+
+		default:
+			value = append(value, char)
+		}
+	}
+
+	if word != nil {
+		word.End = len(line)
+		word.Raw = string(line[word.Start:])
+		word.Value = string(value)
+		word.Unclosed = quote != 0
+		words = append(words, *word)
+	}
+
+	return words
+}
+
+// EscapeWord escapes a word to be inserted in a line after the given quote,
+// or unquoted if quote is 0: in the latter case, all whitespace and special
+// characters are escaped with backslashes. The returned word is not quoted.
+func EscapeWord(word string, quote rune) string {
+	var buf strings.Builder
+
+	for _, char := range word {
+		switch quote {
+		case singleChar:
+			if char == singleChar {
+				buf.WriteString(`'\''`)
+				continue
+			}
+		case doubleChar:
+			if char != '\n' && strings.ContainsRune(doubleEscapeChars, char) {
+				buf.WriteRune(escapeChar)
+			}
+		default:
+			if strings.ContainsRune(quoteChars, char) {
+				buf.WriteRune(escapeChar)
+			}
+		}
+
+		buf.WriteRune(char)
+	}
+
+	return buf.String()
+}
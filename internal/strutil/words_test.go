@@ -0,0 +1,109 @@
+package strutil
+
+import (
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []Word
+	}{
+		{
+			name: "Empty line",
+			line: "",
+			want: []Word{},
+		},
+		{
+			name: "Simple words",
+			line: "ls -l  dir",
+			want: []Word{
+				{Value: "ls", Raw: "ls", Start: 0, End: 2},
+				{Value: "-l", Raw: "-l", Start: 3, End: 5},
+				{Value: "dir", Raw: "dir", Start: 7, End: 10},
+			},
+		},
+		{
+			name: "Quoted and escaped words",
+			line: `cat 'my file' "it's" my\ dir`,
+			want: []Word{
+				{Value: "cat", Raw: "cat", Start: 0, End: 3},
+				{Value: "my file", Raw: "'my file'", Start: 4, End: 13, Quote: '\''},
+				{Value: "it's", Raw: `"it's"`, Start: 14, End: 20, Quote: '"'},
+				{Value: "my dir", Raw: `my\ dir`, Start: 21, End: 28},
+			},
+		},
+		{
+			name: "Unclosed quote",
+			line: `cd "my di`,
+			want: []Word{
+				{Value: "cd", Raw: "cd", Start: 0, End: 2},
+				{Value: "my di", Raw: `"my di`, Start: 3, End: 9, Quote: '"', Unclosed: true},
+			},
+		},
+		{
+			name: "Escapes in double quotes",
+			line: `echo "\$HOME \d"`,
+			want: []Word{
+				{Value: "echo", Raw: "echo", Start: 0, End: 4},
+				{Value: `$HOME \d`, Raw: `"\$HOME \d"`, Start: 5, End: 16, Quote: '"'},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Tokenize([]rune(tt.line))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Tokenize() = %+v, want %+v", got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Tokenize() word %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEscapeWord(t *testing.T) {
+	tests := []struct {
+		name  string
+		word  string
+		quote rune
+		want  string
+	}{
+		{
+			name: "Unquoted plain word",
+			word: "file.go",
+			want: "file.go",
+		},
+		{
+			name: "Unquoted word with spaces",
+			word: "my file",
+			want: `my\ file`,
+		},
+		{
+			name:  "Single-quoted word",
+			word:  "it's mine",
+			quote: '\'',
+			want:  `it'\''s mine`,
+		},
+		{
+			name:  "Double-quoted word",
+			word:  `say "$HOME"`,
+			quote: '"',
+			want:  `say \"\$HOME\"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EscapeWord(tt.word, tt.quote); got != tt.want {
+				t.Errorf("EscapeWord() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
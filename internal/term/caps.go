@@ -0,0 +1,161 @@
+package term
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// ColorLevel is the range of colors supported by a terminal.
+type ColorLevel int
+
+// Color levels, from the most limited to the most complete.
+const (
+	ColorNone ColorLevel = iota // No colors nor effects.
+	Color16                     // The 8 standard colors and their bright variants.
+	Color256                    // The xterm 256-color palette.
+	ColorTrue                   // 24-bit RGB colors.
+)
+
+// Capabilities are the features supported by a terminal,
+// which would otherwise be used with hard-coded sequences.
+type Capabilities struct {
+	Colors         ColorLevel
+	GuessedColors  bool // Colors are only assumed from a generic terminal name, and never downgraded.
+	BracketedPaste bool // Pasted text is wrapped in \e[200~ and \e[201~.
+	CursorShape    bool // The cursor shape can be changed with DECSCUSR (\e[N q).
+	SyncOutput     bool // Screen updates can be wrapped in synchronized output (mode 2026).
+	Undercurl      bool // Underlines can be curly (\e[4:3m) and colored (\e[58;5;Nm).
+}
+
+// Caps are the capabilities of the current terminal. They are detected from the
+// environment at startup, and might be refined by querying the terminal itself.
+var Caps = DetectEnv(os.Getenv)
+
+// terminfo is the subset of the terminfo database needed by the shell, indexed by
+// terminal names. The fields are the equivalents of the colors#, Tc/RGB, BE, Ss, Sync
+// and Smulx capabilities. Unknown names are looked up without their last -suffix.
+// Generic names used by many emulators supporting more colors than their entry
+// declares have their colors guessed.
+var terminfo = map[string]Capabilities{
+	"dumb":      {},
+	"vt100":     {},
+	"vt220":     {},
+	"ansi":      {Colors: Color16},
+	"cygwin":    {Colors: Color16},
+	"linux":     {Colors: Color16},
+	"putty":     {Colors: Color16, GuessedColors: true, BracketedPaste: true},
+	"screen":    {Colors: Color16, GuessedColors: true, BracketedPaste: true, CursorShape: true},
+	"tmux":      {Colors: Color16, GuessedColors: true, BracketedPaste: true, CursorShape: true},
+	"xterm":     {Colors: Color16, GuessedColors: true, BracketedPaste: true, CursorShape: true},
+	"rxvt":      {Colors: Color16, GuessedColors: true, BracketedPaste: true, CursorShape: true},
+	"konsole":   {Colors: Color16, GuessedColors: true, BracketedPaste: true, CursorShape: true},
+	"st":        {Colors: Color256, BracketedPaste: true, CursorShape: true, SyncOutput: true},
+	"alacritty": {Colors: ColorTrue, BracketedPaste: true, CursorShape: true, SyncOutput: true, Undercurl: true},
+	"contour":   {Colors: ColorTrue, BracketedPaste: true, CursorShape: true, SyncOutput: true, Undercurl: true},
+	"foot":      {Colors: ColorTrue, BracketedPaste: true, CursorShape: true, SyncOutput: true, Undercurl: true},
+	"wezterm":   {Colors: ColorTrue, BracketedPaste: true, CursorShape: true, SyncOutput: true, Undercurl: true},
+
+	"xterm-kitty":   {Colors: ColorTrue, BracketedPaste: true, CursorShape: true, SyncOutput: true, Undercurl: true},
+	"xterm-ghostty": {Colors: ColorTrue, BracketedPaste: true, CursorShape: true, SyncOutput: true, Undercurl: true},
+}
+
+// terminals are the capabilities of terminals reporting their name with XTVERSION.
+var terminals = map[string]Capabilities{
+	"XTerm":   {Colors: Color256, BracketedPaste: true, CursorShape: true},
+	"tmux":    {Colors: ColorTrue, BracketedPaste: true, CursorShape: true, SyncOutput: true},
+	"kitty":   {Colors: ColorTrue, BracketedPaste: true, CursorShape: true, SyncOutput: true, Undercurl: true},
+	"WezTerm": {Colors: ColorTrue, BracketedPaste: true, CursorShape: true, SyncOutput: true, Undercurl: true},
+	"foot":    {Colors: ColorTrue, BracketedPaste: true, CursorShape: true, SyncOutput: true, Undercurl: true},
+	"iTerm2":  {Colors: ColorTrue, BracketedPaste: true, CursorShape: true, SyncOutput: true, Undercurl: true},
+	"ghostty": {Colors: ColorTrue, BracketedPaste: true, CursorShape: true, SyncOutput: true, Undercurl: true},
+	"contour": {Colors: ColorTrue, BracketedPaste: true, CursorShape: true, SyncOutput: true, Undercurl: true},
+}
+
+// DetectEnv returns the capabilities of the terminal declared in the TERM and
+// COLORTERM environment variables, as read with the getenv function.
+func DetectEnv(getenv func(string) string) Capabilities {
+	name := getenv("TERM")
+
+	// Windows consoles support virtual terminal sequences without declaring it.
+	if name == "" && runtime.GOOS == "windows" {
+		return Capabilities{Colors: ColorTrue, BracketedPaste: true, CursorShape: true}
+	}
+
+	caps, found := lookupTerminfo(name)
+
+	// Unknown terminals are assumed to be xterm-compatible, as they mostly are.
+	if !found && name != "" {
+		caps = terminfo["xterm"]
+	}
+
+	if caps.Colors == ColorNone {
+		return caps
+	}
+
+	switch {
+	case strings.HasSuffix(name, "-direct"), strings.HasSuffix(name, "-truecolor"):
+		caps.Colors, caps.GuessedColors = ColorTrue, false
+	case strings.HasSuffix(name, "-256color"):
+		caps.Colors, caps.GuessedColors = max(caps.Colors, Color256), false
+	}
+
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		caps.Colors, caps.GuessedColors = ColorTrue, false
+	}
+
+	return caps
+}
+
+// Update refines the capabilities with the answers of the terminal to queries: the
+// parameters of its primary device attributes (DA1), the name and version it reports
+// with XTVERSION (might be empty), and its DECRQM report for synchronized output
+// (-1 if the terminal did not answer).
+func (c *Capabilities) Update(attributes []int, version string, syncMode int) {
+	name, _, _ := strings.Cut(strings.TrimSpace(version), " ")
+	name, _, _ = strings.Cut(name, "(")
+
+	if caps, found := terminals[name]; found {
+		c.Colors = max(c.Colors, caps.Colors)
+		c.GuessedColors = false
+		c.BracketedPaste = c.BracketedPaste || caps.BracketedPaste
+		c.CursorShape = c.CursorShape || caps.CursorShape
+		c.SyncOutput = c.SyncOutput || caps.SyncOutput
+		c.Undercurl = c.Undercurl || caps.Undercurl
+	}
+
+	// Parameter 22 is ANSI color support.
+	for _, attr := range attributes {
+		if attr == 22 && c.Colors == ColorNone {
+			c.Colors = Color16
+		}
+	}
+
+	// DECRQM modes: 1 (set) and 2 (reset) are supported, 0 (unknown) and 4 are not.
+	switch syncMode {
+	case 1, 2:
+		c.SyncOutput = true
+	case 0, 4:
+		c.SyncOutput = false
+	}
+}
+
+// lookupTerminfo returns the capabilities for a terminal name,
+// or those of its base name (xterm-256color -> xterm) if unknown.
+func lookupTerminfo(name string) (caps Capabilities, found bool) {
+	for name != "" {
+		if caps, found = terminfo[name]; found {
+			return caps, found
+		}
+
+		sep := strings.LastIndex(name, "-")
+		if sep < 0 {
+			break
+		}
+
+		name = name[:sep]
+	}
+
+	return caps, false
+}
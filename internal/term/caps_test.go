@@ -0,0 +1,94 @@
+package term
+
+import "testing"
+
+func TestDetectEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want Capabilities
+	}{
+		{
+			name: "No terminal",
+			env:  map[string]string{},
+			want: Capabilities{},
+		},
+		{
+			name: "Dumb terminal",
+			env:  map[string]string{"TERM": "dumb", "COLORTERM": "truecolor"},
+			want: Capabilities{},
+		},
+		{
+			name: "xterm",
+			env:  map[string]string{"TERM": "xterm"},
+			want: Capabilities{Colors: Color16, GuessedColors: true, BracketedPaste: true, CursorShape: true},
+		},
+		{
+			name: "256 colors",
+			env:  map[string]string{"TERM": "screen-256color"},
+			want: Capabilities{Colors: Color256, BracketedPaste: true, CursorShape: true},
+		},
+		{
+			name: "Base name",
+			env:  map[string]string{"TERM": "rxvt-unicode-256color"},
+			want: Capabilities{Colors: Color256, BracketedPaste: true, CursorShape: true},
+		},
+		{
+			name: "COLORTERM",
+			env:  map[string]string{"TERM": "tmux-256color", "COLORTERM": "truecolor"},
+			want: Capabilities{Colors: ColorTrue, BracketedPaste: true, CursorShape: true},
+		},
+		{
+			name: "Known terminal",
+			env:  map[string]string{"TERM": "xterm-kitty"},
+			want: Capabilities{Colors: ColorTrue, BracketedPaste: true, CursorShape: true, SyncOutput: true, Undercurl: true},
+		},
+		{
+			name: "Console",
+			env:  map[string]string{"TERM": "linux"},
+			want: Capabilities{Colors: Color16},
+		},
+		{
+			name: "Unknown terminal",
+			env:  map[string]string{"TERM": "myterm"},
+			want: Capabilities{Colors: Color16, GuessedColors: true, BracketedPaste: true, CursorShape: true},
+		},
+		{
+			name: "Unknown truecolor terminal",
+			env:  map[string]string{"TERM": "myterm-direct"},
+			want: Capabilities{Colors: ColorTrue, BracketedPaste: true, CursorShape: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := DetectEnv(func(key string) string { return test.env[key] }); got != test.want {
+				t.Errorf("DetectEnv() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCapabilitiesUpdate(t *testing.T) {
+	caps := Capabilities{}
+	caps.Update([]int{62, 22}, "", 4)
+
+	if want := (Capabilities{Colors: Color16}); caps != want {
+		t.Errorf("Update(DA1) = %+v, want %+v", caps, want)
+	}
+
+	caps = Capabilities{Colors: Color16, GuessedColors: true, BracketedPaste: true}
+	caps.Update([]int{65}, "WezTerm 20240203-110809-5046fc22", 2)
+
+	want := Capabilities{Colors: ColorTrue, BracketedPaste: true, CursorShape: true, SyncOutput: true, Undercurl: true}
+	if caps != want {
+		t.Errorf("Update(XTVERSION) = %+v, want %+v", caps, want)
+	}
+
+	caps = Capabilities{Colors: Color256, SyncOutput: true}
+	caps.Update([]int{1, 2}, "", 0)
+
+	if want := (Capabilities{Colors: Color256}); caps != want {
+		t.Errorf("Update(DECRQM) = %+v, want %+v", caps, want)
+	}
+}
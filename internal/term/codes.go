@@ -3,6 +3,9 @@ package term
 // Terminal control sequences.
 const (
 	NewlineReturn = "\r\n"
+	Bell          = "\a"
+	FlashStart    = "\x1b[?5h" // Reverse video on the whole screen (DECSCNM).
+	FlashEnd      = "\x1b[?5l"
 
 	ClearLineAfter   = "\x1b[0K"
 	ClearLineBefore  = "\x1b[1K"
@@ -19,6 +22,21 @@ const (
 
 	BracketedPasteStart = "\x1b[?2004h"
 	BracketedPasteEnd   = "\x1b[?2004l"
+
+	SyncUpdateStart = "\x1b[?2026h" // Screen updates are deferred until SyncUpdateEnd.
+	SyncUpdateEnd   = "\x1b[?2026l"
+
+	MouseTrackingStart = "\x1b[?1000h\x1b[?1006h" // Button and wheel events, SGR encoded.
+	MouseTrackingEnd   = "\x1b[?1006l\x1b[?1000l"
+
+	DeviceAttributesQuery = "\x1b[c"   // Primary device attributes (DA1), answered by all terminals.
+	KittyKeyboardQuery    = "\x1b[?u"  // Current flags of the kitty keyboard protocol.
+	KittyKeyboardPush     = "\x1b[>1u" // Disambiguate escape codes.
+	KittyKeyboardPop      = "\x1b[<u"
+	ModifyOtherKeysStart  = "\x1b[>4;2m"
+	ModifyOtherKeysEnd    = "\x1b[>4m"
+	VersionQuery          = "\x1b[>0q"     // Terminal name and version (XTVERSION).
+	SyncOutputQuery       = "\x1b[?2026$p" // Support for synchronized output (DECRQM).
 )
 
 // Some core keys needed by some stuff.
@@ -62,4 +62,14 @@ func EnableBracketedPaste() {
 // DisableBracketedPaste disables bracketed paste mode.
 func DisableBracketedPaste() {
 	fmt.Print(BracketedPasteEnd)
-}
\ No newline at end of file
+}
+
+// EnableMouse enables the reporting of mouse button and wheel events.
+func EnableMouse() {
+	fmt.Print(MouseTrackingStart)
+}
+
+// DisableMouse disables the reporting of mouse events.
+func DisableMouse() {
+	fmt.Print(MouseTrackingEnd)
+}
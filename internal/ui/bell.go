@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/reeflective/readline/inputrc"
+	"github.com/reeflective/readline/internal/term"
+)
+
+// flashDuration is how long the screen is reversed with a visible bell.
+var flashDuration = 100 * time.Millisecond
+
+// Bell notifies the user that a command failed, like a search without matches or a
+// completion without candidates. Like the hint, other components have access to it.
+type Bell struct {
+	onRing func()
+	config *inputrc.Config
+}
+
+// NewBell is a required constructor to use for initializing the bell.
+func NewBell(config *inputrc.Config) *Bell {
+	return &Bell{config: config}
+}
+
+// OnRing sets a function called each time the bell rings, whatever the bell style,
+// so that applications can notify the user themselves. Set to nil to disable again.
+func (b *Bell) OnRing(callback func()) {
+	b.onRing = callback
+}
+
+// Ring rings the bell according to the bell-style option: with the terminal bell
+// (audible), by briefly reversing the screen colors (visible), or not at all (none).
+func (b *Bell) Ring() {
+	if b.onRing != nil {
+		b.onRing()
+	}
+
+	switch b.config.GetString("bell-style") {
+	case "none", "off":
+	case "visible":
+		fmt.Print(term.FlashStart)
+		time.Sleep(flashDuration)
+		fmt.Print(term.FlashEnd)
+	default:
+		fmt.Print(term.Bell)
+	}
+}
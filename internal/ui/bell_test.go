@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/reeflective/readline/inputrc"
+	"github.com/reeflective/readline/internal/term"
+)
+
+func TestBellRing(t *testing.T) {
+	defer func(duration time.Duration) { flashDuration = duration }(flashDuration)
+	flashDuration = 0
+
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{style: "audible", want: term.Bell},
+		{style: "visible", want: term.FlashStart + term.FlashEnd},
+		{style: "none", want: ""},
+		{style: "off", want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.style, func(t *testing.T) {
+			config := inputrc.NewDefaultConfig()
+			config.Set("bell-style", test.style)
+
+			bell := NewBell(config)
+
+			var rings int
+			bell.OnRing(func() { rings++ })
+
+			if output := printed(t, bell.Ring); output != test.want {
+				t.Errorf("Ring() printed %q, want %q", output, test.want)
+			}
+
+			if rings != 1 {
+				t.Errorf("Ring() called the OnRing callback %d times, want 1", rings)
+			}
+
+			bell.OnRing(nil)
+			printed(t, bell.Ring)
+
+			if rings != 1 {
+				t.Errorf("Ring() called a removed OnRing callback")
+			}
+		})
+	}
+}
+
+// printed returns what a function prints on stdout.
+func printed(t *testing.T, print func()) string {
+	t.Helper()
+
+	stdout := os.Stdout
+	defer func() { os.Stdout = stdout }()
+
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	os.Stdout = out
+
+	print()
+
+	output, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(output)
+}
@@ -70,13 +70,9 @@ func (h *Hint) ResetPersist() {
 
 // DisplayHint prints the hint (persistent and/or temporary) sections.
 func DisplayHint(hint *Hint) {
-	if hint.temp && hint.set {
-		hint.set = false
-	} else if hint.temp {
-		hint.Reset()
-	}
+	text := RenderHint(hint)
 
-	if len(hint.text) == 0 && len(hint.persistent) == 0 {
+	if text == "" {
 		if hint.cleanup {
 			fmt.Print(term.ClearLineAfter)
 		}
@@ -86,17 +82,29 @@ func DisplayHint(hint *Hint) {
 		return
 	}
 
-	text := hint.renderHint()
+	fmt.Print(text + term.ClearLineAfter + color.Reset)
+}
 
-	if strutil.RealLength(text) == 0 {
-		return
+// RenderHint returns the hint (persistent and/or temporary) sections as printed
+// by DisplayHint(), each line ending with a newline, or nothing if there is no hint.
+func RenderHint(hint *Hint) string {
+	if hint.temp && hint.set {
+		hint.set = false
+	} else if hint.temp {
+		hint.Reset()
+	}
+
+	if len(hint.text) == 0 && len(hint.persistent) == 0 {
+		return ""
 	}
 
-	text += term.ClearLineAfter + color.Reset
+	text := hint.renderHint()
 
-	if len(text) > 0 {
-		fmt.Print(text)
+	if strutil.RealLength(text) == 0 {
+		return ""
 	}
+
+	return text
 }
 
 func (h *Hint) renderHint() (text string) {
@@ -31,6 +31,7 @@ type Prompt struct {
 	transientF func() string
 	rightF     func() string
 	tooltipF   func() string
+	toolbarF   func() string
 
 	// True if some logs have printed asynchronously
 	// since last loop. Check refresh prompt funcs.
@@ -73,6 +74,12 @@ func (p *Prompt) Transient(prompt func() string) {
 	p.transientF = prompt
 }
 
+// Toolbar uses a function returning the status line displayed below the input
+// line and its helpers, which is re-evaluated each time the line is refreshed.
+func (p *Prompt) Toolbar(prompt func() string) {
+	p.toolbarF = prompt
+}
+
 // Tooltip uses a function returning the prompt to use as a tooltip prompt.
 func (p *Prompt) Tooltip(prompt func(word string) string) {
 	if prompt == nil {
@@ -132,8 +139,13 @@ func (p *Prompt) PrimaryUsed() int {
 // spans on several lines. If not, this function will actually print
 // the entire primary prompt, and PrimaryPrint() will not print anything.
 func (p *Prompt) LastPrint() {
+	fmt.Print(p.RenderLast())
+}
+
+// RenderLast returns the last line of the primary prompt as printed by LastPrint().
+func (p *Prompt) RenderLast() string {
 	if p.primaryF == nil {
-		return
+		return ""
 	}
 
 	// Only display the last line, but overwrite the number of
@@ -141,16 +153,16 @@ func (p *Prompt) LastPrint() {
 	// will trigger their  own recomputation.
 	lines := strings.Split(p.primaryF(), "\n")
 
-	// Print the prompt and compute columns.
+	// Render the prompt and compute columns.
 	if len(lines) == 0 {
-		return
+		return ""
 	}
 
 	prompt := p.formatLastPrompt(lines[len(lines)-1])
 
-	fmt.Print(prompt)
-
 	p.primaryCols = strutil.RealLength(prompt)
+
+	return prompt
 }
 
 // LastUsed returns the number of terminal columns used by the last
@@ -179,12 +191,16 @@ func (p *Prompt) LastUsed() int {
 // SecondaryPrint prints the last cursor in secondary prompt mode,
 // which is always activated when the current input line is a multiline one.
 func (p *Prompt) SecondaryPrint() {
+	fmt.Print(p.RenderSecondary())
+}
+
+// RenderSecondary returns the secondary prompt as printed by SecondaryPrint().
+func (p *Prompt) RenderSecondary() string {
 	if p.secondaryF != nil {
-		fmt.Print(p.secondaryF())
-		return
+		return p.secondaryF()
 	}
 
-	fmt.Print(DefaultSecondaryPrompt)
+	return DefaultSecondaryPrompt
 }
 
 // MultilineColumnPrint prints the multiline editor column status indicator.
@@ -221,16 +237,7 @@ func (p *Prompt) MultilineColumnPrint() {
 // If force is true, whatever rprompt or tooltip exists will be printed.
 // If false, only the rprompt, if it exists, will be printed.
 func (p *Prompt) RightPrint(startColumn int, force bool) {
-	var rprompt string
-
-	if p.tooltipF != nil && force {
-		rprompt = p.tooltipF()
-	}
-
-	if rprompt == "" && p.rightF != nil {
-		rprompt = p.rightF()
-	}
-
+	rprompt := p.rightPrompt(force)
 	if rprompt == "" {
 		return
 	}
@@ -242,6 +249,19 @@ func (p *Prompt) RightPrint(startColumn int, force bool) {
 	}
 }
 
+// RenderRight returns the right-sided prompt as printed by RightPrint(), padded
+// from the start column, or nothing if there is none or not enough room for it.
+func (p *Prompt) RenderRight(startColumn int, force bool) string {
+	rprompt := p.rightPrompt(force)
+	if rprompt == "" {
+		return ""
+	}
+
+	prompt, _ := p.formatRightPrompt(rprompt, startColumn)
+
+	return prompt
+}
+
 // TransientPrint prints the transient prompt.
 func (p *Prompt) TransientPrint() {
 	if p.transientF == nil {
@@ -257,6 +277,20 @@ func (p *Prompt) TransientPrint() {
 	fmt.Print(p.transientF())
 }
 
+// RenderToolbar returns the status line, or nothing if there is none.
+func (p *Prompt) RenderToolbar() string {
+	if p.toolbarF == nil {
+		return ""
+	}
+
+	return p.toolbarF()
+}
+
+// HasToolbar returns true if a status line is used.
+func (p *Prompt) HasToolbar() bool {
+	return p.toolbarF != nil
+}
+
 // Refreshing returns true if the prompt is currently redisplaying
 // itself (at least the primary prompt), or false if not.
 func (p *Prompt) Refreshing() bool {
@@ -294,6 +328,18 @@ func (p *Prompt) formatLastPrompt(prompt string) string {
 	return status + prompt
 }
 
+func (p *Prompt) rightPrompt(force bool) (rprompt string) {
+	if p.tooltipF != nil && force {
+		rprompt = p.tooltipF()
+	}
+
+	if rprompt == "" && p.rightF != nil {
+		rprompt = p.rightF()
+	}
+
+	return rprompt
+}
+
 func (p *Prompt) formatRightPrompt(rprompt string, startColumn int) (prompt string, canPrint bool) {
 	// Dimensions
 	termWidth := term.GetWidth()
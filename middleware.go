@@ -0,0 +1,63 @@
+package readline
+
+// CompletionMiddleware transforms completions after they have been generated by
+// the shell completer, and before they are handed to the completion engine: it
+// can filter, sort and restyle candidates, merge other completions, or rewrite
+// messages. Middlewares run on the completions once generated or retrieved from
+// the cache (see Completions.Cache), so they always apply to the current context.
+type CompletionMiddleware func(ctx CompletionContext, comps Completions) Completions
+
+// completionMiddleware is a middleware, applied either
+// to all completions or to those with a given tag only.
+type completionMiddleware struct {
+	tag        string
+	middleware CompletionMiddleware
+}
+
+// UseCompletionMiddleware registers middlewares applied to all completions.
+// Middlewares, whether global or per tag, run in their registration order.
+//
+//	shell.UseCompletionMiddleware(func(ctx readline.CompletionContext, comps readline.Completions) readline.Completions {
+//		return comps.FilterF(func(c readline.Completion) bool {
+//			return !strings.HasPrefix(c.Value, "__")
+//		})
+//	})
+func (rl *Shell) UseCompletionMiddleware(middlewares ...CompletionMiddleware) {
+	for _, middleware := range middlewares {
+		rl.middlewares = append(rl.middlewares, completionMiddleware{middleware: middleware})
+	}
+}
+
+// UseTagCompletionMiddleware registers middlewares applied to completions with a given tag:
+// they are passed the candidates with this tag only (along with all completion settings and
+// messages), and the candidates they return replace these ones. Settings and messages are
+// left untouched.
+func (rl *Shell) UseTagCompletionMiddleware(tag string, middlewares ...CompletionMiddleware) {
+	for _, middleware := range middlewares {
+		rl.middlewares = append(rl.middlewares, completionMiddleware{tag: tag, middleware: middleware})
+	}
+}
+
+// applyMiddlewares applies all registered middlewares to resolved completions.
+// Completions returned by middlewares are resolved in turn, if they are deferred.
+func (rl *Shell) applyMiddlewares(ctx CompletionContext, comps Completions) Completions {
+	for _, mw := range rl.middlewares {
+		if mw.tag == "" {
+			comps = mw.middleware(ctx, comps)
+			comps = comps.resolve(rl.completer.Results())
+
+			continue
+		}
+
+		tagged, others := comps.splitTag(mw.tag)
+		if len(tagged.values) == 0 {
+			continue
+		}
+
+		tagged = mw.middleware(ctx, tagged)
+		tagged = tagged.resolve(rl.completer.Results())
+		comps.values = append(others, tagged.values...)
+	}
+
+	return comps
+}
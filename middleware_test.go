@@ -0,0 +1,126 @@
+package readline
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/reeflective/readline/internal/completion"
+)
+
+// completeLine returns the shell completions for a line, with the cursor at its end.
+func completeLine(rl *Shell, line string) completion.Values {
+	rl.line.Set([]rune(line)...)
+	rl.cursor.Set(rl.line.Len())
+
+	return rl.commandCompletion()
+}
+
+// valueNames returns the values of completion candidates.
+func valueNames(values completion.Values) (names []string) {
+	for _, val := range values.Raw() {
+		names = append(names, val.Value)
+	}
+
+	return names
+}
+
+func TestCompletionMiddlewaresOrder(t *testing.T) {
+	var seen [][]string
+
+	rl := NewShell()
+	rl.Completer = func(line []rune, cursor int) Completions {
+		return CompleteValues("build", "__debug", "test")
+	}
+
+	rl.UseCompletionMiddleware(
+		func(ctx CompletionContext, comps Completions) Completions {
+			seen = append(seen, valueNames(comps.convert()))
+			return comps.FilterF(func(c Completion) bool { return !strings.HasPrefix(c.Value, "__") })
+		},
+		func(ctx CompletionContext, comps Completions) Completions {
+			seen = append(seen, valueNames(comps.convert()))
+			return comps.Merge(CompleteValues("help"))
+		},
+	)
+
+	want := [][]string{{"build", "__debug", "test"}, {"build", "test"}}
+	if values := completeLine(rl, "go "); len(values.Raw()) != 3 || !slices.EqualFunc(seen, want, slices.Equal) {
+		t.Errorf("middlewares saw %q and returned %q, want %q and 3 values", seen, valueNames(values), want)
+	}
+}
+
+func TestCompletionMiddlewaresTag(t *testing.T) {
+	var seen []string
+
+	rl := NewShell()
+	rl.Completer = func(line []rune, cursor int) Completions {
+		files := CompleteValues("main.go", "go.mod").Tag("files")
+		dirs := CompleteValues("cmd/", "internal/").Tag("directories")
+
+		return files.Merge(dirs).Usage("go files").AddMessage(MessageWarning, "directories", "vendor ignored")
+	}
+
+	rl.UseTagCompletionMiddleware("files", func(ctx CompletionContext, comps Completions) Completions {
+		seen = valueNames(comps.convert())
+		return CompleteValues("main.go").Tag("files").Usage("other usage")
+	})
+
+	values := completeLine(rl, "go build ")
+
+	slices.Sort(seen)
+
+	if want := []string{"go.mod", "main.go"}; !slices.Equal(seen, want) {
+		t.Errorf("tag middleware saw %q, want %q", seen, want)
+	}
+
+	got := valueNames(values)
+	slices.Sort(got)
+
+	if want := []string{"cmd/", "internal/", "main.go"}; !slices.Equal(got, want) {
+		t.Errorf("completions after tag middleware = %q, want %q", got, want)
+	}
+
+	if values.Usage != "go files" || len(values.Messages.Tagged("directories")) != 1 {
+		t.Errorf("completions after tag middleware changed settings: usage %q, messages %v",
+			values.Usage, values.Messages.All())
+	}
+}
+
+func TestCompletionMiddlewaresCache(t *testing.T) {
+	var calls int
+
+	rl := NewShell()
+	rl.ContextCompleter = func(ctx CompletionContext) Completions {
+		return CompleteFunc(func() Completions {
+			calls++
+			return CompleteValues("apply", "delete", "describe")
+		}).Cache("kubectl", 0)
+	}
+
+	// Middlewares use the context of each completion, not the one of the cache fill.
+	rl.UseCompletionMiddleware(func(ctx CompletionContext, comps Completions) Completions {
+		return comps.FilterF(func(c Completion) bool { return strings.HasPrefix(c.Value, ctx.Prefix) })
+	})
+
+	if got := valueNames(completeLine(rl, "kubectl a")); !slices.Equal(got, []string{"apply"}) {
+		t.Errorf("completions for %q = %q, want %q", "kubectl a", got, []string{"apply"})
+	}
+
+	if got, want := valueNames(completeLine(rl, "kubectl de")), []string{"delete", "describe"}; !slices.Equal(got, want) {
+		t.Errorf("completions for %q = %q, want %q", "kubectl de", got, want)
+	}
+
+	// Middlewares registered later apply to cached completions.
+	rl.UseCompletionMiddleware(func(ctx CompletionContext, comps Completions) Completions {
+		return comps.Style("31")
+	})
+
+	if values := completeLine(rl, "kubectl d"); len(values.Raw()) != 2 || values.Raw()[0].Style != "31" {
+		t.Errorf("completions after new middleware = %+v, want styled values", values.Raw())
+	}
+
+	if calls != 1 {
+		t.Errorf("cached completions generated %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,81 @@
+package readline
+
+import (
+	"github.com/reeflective/readline/internal/core"
+	"github.com/reeflective/readline/internal/keymap"
+)
+
+// mouseCommands returns the commands bound to mouse events. Mouse events
+// are only reported by the terminal when the enable-mouse option is on.
+func (rl *Shell) mouseCommands() commands {
+	return map[string]func(){
+		"mouse-click":       rl.mouseClick,
+		"mouse-scroll-up":   rl.mouseScrollUp,
+		"mouse-scroll-down": rl.mouseScrollDown,
+	}
+}
+
+//
+// Commands ---------------------------------------------------------------------------
+//
+
+// Select the completion candidate under the mouse pointer if any,
+// or move the cursor to the clicked position of the input line.
+func (rl *Shell) mouseClick() {
+	rl.History.SkipSave()
+
+	event, found := core.PopMouse(rl.Keys)
+	if !found {
+		return
+	}
+
+	if row, found := rl.Display.CompletionRowAt(event.Y); found {
+		if rl.completer.SelectAt(row, event.X-1) {
+			return
+		}
+	}
+
+	// The position is the one on the displayed line, which
+	// includes any candidate inserted, so accept it first.
+	pos, found := rl.Display.LinePosAt(event.X, event.Y)
+	if !found {
+		return
+	}
+
+	rl.completer.Reset()
+	rl.cursor.Set(pos)
+
+	if rl.Keymap.Main() == keymap.ViCommand {
+		rl.cursor.CheckCommand()
+	}
+}
+
+// Select the previous completion candidate if completing,
+// or move back through the history list otherwise.
+func (rl *Shell) mouseScrollUp() {
+	core.PopMouse(rl.Keys)
+
+	if rl.completer.IsActive() {
+		rl.History.SkipSave()
+		rl.completer.Select(-1, 0)
+
+		return
+	}
+
+	rl.upHistory()
+}
+
+// Select the next completion candidate if completing,
+// or move forward through the history list otherwise.
+func (rl *Shell) mouseScrollDown() {
+	core.PopMouse(rl.Keys)
+
+	if rl.completer.IsActive() {
+		rl.History.SkipSave()
+		rl.completer.Select(1, 0)
+
+		return
+	}
+
+	rl.downHistory()
+}
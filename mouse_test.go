@@ -0,0 +1,40 @@
+package readline
+
+import (
+	"testing"
+
+	"github.com/reeflective/readline/inputrc"
+)
+
+func TestMouseBinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		bind     string
+		wantLine string
+	}{
+		{
+			name:     "Unbound right click",
+			wantLine: "cab",
+		},
+		{
+			name:     "Bound right click",
+			bind:     "end-of-line",
+			wantLine: "abc",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rl := NewShell()
+			rl.Config.Set("enable-mouse", true)
+
+			if test.bind != "" {
+				rl.Config.Bind("emacs", inputrc.Unescape(`\e[<2M`), test.bind, false)
+			}
+
+			if line, _ := readKeys(t, rl, "ab\x01\x1b[<2;1;1M\x1b[<2;1;1mc\r"); line != test.wantLine {
+				t.Errorf("Readline() = %q, want %q", line, test.wantLine)
+			}
+		})
+	}
+}
@@ -60,11 +60,24 @@ func (rl *Shell) Readline() (string, error) {
 		defer term.Restore(descriptor, state)
 	}
 
-	if term.IsTerminal(descriptor) && rl.Config.GetBool("enable-bracketed-paste") {
+	if term.IsTerminal(descriptor) && rl.Config.GetBool("query-terminal") {
+		rl.Keys.DetectCapabilities()
+	}
+
+	if term.IsTerminal(descriptor) && rl.Config.GetBool("enable-bracketed-paste") && term.Caps.BracketedPaste {
 		term.EnableBracketedPaste()
 		defer term.DisableBracketedPaste()
 	}
 
+	if term.IsTerminal(descriptor) && rl.Config.GetBool("enable-mouse") {
+		term.EnableMouse()
+		defer term.DisableMouse()
+	}
+
+	if term.IsTerminal(descriptor) && rl.Config.GetBool("enable-extended-keys") {
+		defer fmt.Print(rl.Keys.EnableExtendedKeys())
+	}
+
 	// Prompts and cursor styles
 	rl.Display.PrintPrimaryPrompt()
 	defer rl.Display.RefreshTransient()
@@ -72,10 +85,17 @@ func (rl *Shell) Readline() (string, error) {
 
 	rl.init()
 
+	// Rankings of accepted candidates are saved once per line.
+	defer rl.completer.SaveFrecency()
+
 	// Terminal resize events
 	resize := display.WatchResize(rl.Display)
 	defer close(resize)
 
+	// Periodic status line updates
+	toolbar := display.WatchToolbar(rl.Display, rl.Config.GetInt("toolbar-refresh-interval"))
+	defer close(toolbar)
+
 	for {
 		// Whether or not the command is resolved, let the macro
 		// engine record the keys if currently recording a macro.
@@ -159,7 +179,8 @@ func (rl *Shell) init() {
 	// Reset/initialize user interface components.
 	rl.Hint.Reset()
 	rl.completer.ResetForce()
-	display.Init(rl.Display, rl.SyntaxHighlighter)
+	display.Init(rl.Display, rl.SyntaxHighlighter, rl.SpanHighlighter, rl.Validator)
+	rl.refused = nil
 }
 
 // run wraps the execution of a target command/sequence with various pre/post actions
@@ -216,6 +237,12 @@ func (rl *Shell) execute(command func()) {
 		command()
 	}
 
+	// An accepted completion candidate may ask for new completions
+	// to be generated: not finding any is not a failed command.
+	if rl.completer.FollowUp() {
+		rl.generateMenu(rl.commandCompletion)
+	}
+
 	// Only run pending-operator commands when the command we
 	// just executed has not had any influence on iterations.
 	if !rl.Iterations.IsPending() {
@@ -0,0 +1,37 @@
+package readline
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/reeflective/readline/internal/core"
+)
+
+// readKeys runs the shell with the keys as its whole input, and returns the line
+// read (empty if the input ended before a line was accepted), and all the output.
+func readKeys(t *testing.T, rl *Shell, keys string) (line, output string) {
+	t.Helper()
+
+	stdin, stdout := core.Stdin, os.Stdout
+	defer func() { core.Stdin, os.Stdout = stdin, stdout }()
+
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	core.Stdin = io.NopCloser(strings.NewReader(keys))
+	os.Stdout = out
+
+	line, _ = rl.Readline()
+
+	printed, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return line, string(printed)
+}
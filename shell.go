@@ -40,6 +40,7 @@ type Shell struct {
 	Opts      []inputrc.Option   // Inputrc file parsing options (app/term/values, etc).
 	Prompt    *ui.Prompt         // The prompt engine computes and renders prompt strings.
 	Hint      *ui.Hint           // Usage/hints for completion/isearch below the input line.
+	Bell      *ui.Bell           // Rings when commands fail (no matches, no completions, etc).
 	completer *completion.Engine // Completions generation and display.
 	Display   *display.Engine    // Manages display refresh/update/clearing.
 
@@ -57,12 +58,57 @@ type Shell struct {
 	// Once enabled, set to nil to disable again.
 	SyntaxHighlighter func(line []rune) string
 
+	// SpanHighlighter provides syntax highlighting as styled ranges of the line,
+	// and is used instead of SyntaxHighlighter when set. The shell merges these
+	// spans with its own highlighting (selections, searches, etc.), and caches
+	// them for each line content until the next call to Readline().
+	SpanHighlighter func(line []rune) []Span
+
+	// Validator checks the input line as it is typed, and returns the problems found in
+	// it. Their ranges are underlined, and the message of the one under the cursor is
+	// shown as a hint. A line with errors is not accepted, unless accept-line is used
+	// again on the same line.
+	Validator func(line []rune) []Diagnostic
+
 	// Completer is a function that produces completions.
 	// It takes the readline line ([]rune) and cursor pos as parameters,
 	// and returns completions with their associated metadata/settings.
 	Completer func(line []rune, cursor int) Completions
+
+	// ContextCompleter is like Completer, but it is passed the input line already
+	// split into shell words, along with the word under the cursor and its quoting
+	// state. When set, it is used instead of Completer. Unless the completions have
+	// their PREFIX set, their values are automatically quoted/escaped like the word
+	// under the cursor, and PREFIX/SUFFIX are set to the raw parts of this word.
+	ContextCompleter func(ctx CompletionContext) Completions
+
+	// Completion middlewares, in registration order.
+	middlewares []completionMiddleware
+
+	// The last line refused by accept-line because of its errors.
+	refused *string
 }
 
+// Span is a range of runes in the input line, from Start (included) to End (excluded),
+// highlighted with a style made of one or more SGR sequences (colors and effects).
+// When spans overlap, the last ones override the previous ones.
+type Span = display.Span
+
+// Diagnostic is a problem found by the shell Validator in the input
+// line, on a range of runes from Start (included) to End (excluded).
+type Diagnostic = display.Diagnostic
+
+// Severity is the severity of a diagnostic, which determines its style
+// (the diagnostic-*-style options), and whether it prevents accepting the line.
+type Severity = display.Severity
+
+// Diagnostic severities.
+const (
+	SeverityInfo    = display.SeverityInfo
+	SeverityWarning = display.SeverityWarning
+	SeverityError   = display.SeverityError
+)
+
 // NewShell returns a readline shell instance initialized with a default
 // inputrc configuration and binds, and with an in-memory command history.
 // The constructor accepts an optional list of inputrc configuration options,
@@ -90,6 +136,7 @@ func NewShell(opts ...inputrc.Option) *Shell {
 	keymaps.Register(shell.viCommands())
 	keymaps.Register(shell.historyCommands())
 	keymaps.Register(shell.completionCommands())
+	keymaps.Register(shell.mouseCommands())
 
 	shell.Keymap = keymaps
 	shell.Config = config
@@ -97,16 +144,19 @@ func NewShell(opts ...inputrc.Option) *Shell {
 
 	// User interface
 	hint := new(ui.Hint)
+	bell := ui.NewBell(config)
 	prompt := ui.NewPrompt(line, cursor, keymaps, config)
 	macros := macro.NewEngine(keys, hint)
-	history := history.NewSources(line, cursor, hint, config)
-	completer := completion.NewEngine(hint, keymaps, config)
+	history := history.NewSources(line, cursor, hint, bell, config)
+	completer := completion.NewEngine(hint, bell, keymaps, config)
 	completion.Init(completer, keys, line, cursor, selection, shell.commandCompletion)
 
 	display := display.NewEngine(keys, selection, history, prompt, hint, completer, config)
+	completer.SetPreviewRefresh(display.RefreshBackground)
 
 	shell.Config = config
 	shell.Hint = hint
+	shell.Bell = bell
 	shell.Prompt = prompt
 	shell.completer = completer
 	shell.Macros = macros
@@ -0,0 +1,83 @@
+package readline
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/reeflective/readline/internal/completion"
+)
+
+// SystemFiles holds the paths of the system files read by the system completers.
+// Use DefaultSystemFiles for the standard locations, and change them as needed,
+// for instance to point to test fixtures.
+type SystemFiles struct {
+	Passwd     string // Users, /etc/passwd
+	Group      string // Groups, /etc/group
+	Hosts      string // Host names and addresses, /etc/hosts
+	KnownHosts string // SSH known hosts, ~/.ssh/known_hosts
+	SSHConfig  string // SSH client config, ~/.ssh/config
+	Proc       string // Processes, /proc
+}
+
+// DefaultSystemFiles returns the standard locations of system files.
+func DefaultSystemFiles() SystemFiles {
+	home, _ := os.UserHomeDir()
+
+	return SystemFiles{
+		Passwd:     "/etc/passwd",
+		Group:      "/etc/group",
+		Hosts:      "/etc/hosts",
+		KnownHosts: filepath.Join(home, ".ssh", "known_hosts"),
+		SSHConfig:  filepath.Join(home, ".ssh", "config"),
+		Proc:       "/proc",
+	}
+}
+
+// CompleteEnv completes the environment variables of the process, prefixed with a
+// dollar sign and described by their values, under the "environment variables" tag.
+// It is meant to be used when the word being completed starts with a dollar sign.
+func CompleteEnv() Completions {
+	return CompleteRaw(completion.EnvVariables(os.Environ()))
+}
+
+// CompleteUsers completes local users, under the "users" tag.
+func CompleteUsers() Completions {
+	return DefaultSystemFiles().CompleteUsers()
+}
+
+// CompleteGroups completes local groups, under the "groups" tag.
+func CompleteGroups() Completions {
+	return DefaultSystemFiles().CompleteGroups()
+}
+
+// CompleteHosts completes host names from the hosts file and from the SSH
+// known hosts and client configuration files, under the "hosts" tag.
+func CompleteHosts() Completions {
+	return DefaultSystemFiles().CompleteHosts()
+}
+
+// CompleteProcesses completes the IDs of running processes, described
+// by their command names and in numerical order, under the "processes" tag.
+func CompleteProcesses() Completions {
+	return DefaultSystemFiles().CompleteProcesses()
+}
+
+// CompleteUsers completes the users of the passwd file.
+func (f SystemFiles) CompleteUsers() Completions {
+	return CompleteRaw(completion.Users(f.Passwd))
+}
+
+// CompleteGroups completes the groups of the group file.
+func (f SystemFiles) CompleteGroups() Completions {
+	return CompleteRaw(completion.Groups(f.Group))
+}
+
+// CompleteHosts completes the hosts of the hosts, SSH known hosts and SSH config files.
+func (f SystemFiles) CompleteHosts() Completions {
+	return CompleteRaw(completion.Hosts(f.Hosts, f.KnownHosts, f.SSHConfig))
+}
+
+// CompleteProcesses completes the processes of the proc filesystem.
+func (f SystemFiles) CompleteProcesses() Completions {
+	return CompleteRaw(completion.Processes(f.Proc)).NoSort("processes")
+}